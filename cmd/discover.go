@@ -0,0 +1,191 @@
+// cmd/discover.go
+
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/mod/semver"
+	"gopkg.in/yaml.v2"
+
+	"github.com/AlaudaDevops/upgrade-test/pkg/config"
+)
+
+var (
+	discoverFrom              string
+	discoverTo                string
+	discoverStep              string
+	discoverIncludePrerelease bool
+	discoverOutput            string
+)
+
+var discoverCmd = &cobra.Command{
+	Use:     "discover <repo-url>",
+	Short:   "Discover upgrade paths from an upstream registry",
+	GroupID: groupManagement,
+	Long: `Discover candidate versions for an operator from its upstream registry
+(git tags today, OCI image tags in the future) and emit a config.UpgradePath
+YAML listing the versions between --from and --to picked by --step (the latest
+patch of every minor by default), so it can be piped directly into
+"upgrade-test --config".`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runDiscover(args[0])
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(discoverCmd)
+
+	discoverCmd.Flags().StringVar(&discoverFrom, "from", "", "lower bound of the version window (default: earliest tag)")
+	discoverCmd.Flags().StringVar(&discoverTo, "to", "latest", "upper bound of the version window")
+	discoverCmd.Flags().StringVar(&discoverStep, "step", "minor", "granularity of the upgrade path: minor, patch or all")
+	discoverCmd.Flags().BoolVar(&discoverIncludePrerelease, "include-prerelease", false, "include pre-release tags in the discovered path")
+	discoverCmd.Flags().StringVar(&discoverOutput, "output", "", "file to write the generated config to, defaults to stdout")
+}
+
+func runDiscover(repoURL string) error {
+	tags, err := listGitTags(repoURL)
+	if err != nil {
+		return fmt.Errorf("failed to list tags: %v", err)
+	}
+
+	versions, err := resolveVersions(tags, discoverFrom, discoverTo, discoverStep, discoverIncludePrerelease)
+	if err != nil {
+		return fmt.Errorf("failed to resolve upgrade path: %v", err)
+	}
+	if len(versions) == 0 {
+		return fmt.Errorf("no versions found for %s in range [%s, %s]", repoURL, discoverFrom, discoverTo)
+	}
+
+	path := config.UpgradePath{
+		Name: fmt.Sprintf("discover-%s", strings.TrimSuffix(baseName(repoURL), ".git")),
+	}
+	for _, v := range versions {
+		path.Versions = append(path.Versions, config.Version{
+			Name:          v,
+			Channel:       "stable",
+			BundleVersion: v,
+		})
+	}
+
+	out, err := yaml.Marshal(config.Config{UpgradePaths: []config.UpgradePath{path}})
+	if err != nil {
+		return fmt.Errorf("failed to render config: %v", err)
+	}
+
+	if discoverOutput == "" {
+		_, err = os.Stdout.Write(out)
+		return err
+	}
+	return os.WriteFile(discoverOutput, out, 0644)
+}
+
+// listGitTags enumerates tags from the upstream repository via `git ls-remote --tags`
+func listGitTags(repoURL string) ([]string, error) {
+	cmd := exec.Command("git", "ls-remote", "--tags", repoURL)
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return nil, err
+	}
+
+	var tags []string
+	scanner := bufio.NewScanner(&stdout)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		ref := fields[1]
+		// Skip dereferenced annotated-tag entries (refs/tags/v1.0.0^{})
+		if strings.HasSuffix(ref, "^{}") {
+			continue
+		}
+		tags = append(tags, strings.TrimPrefix(ref, "refs/tags/"))
+	}
+	return tags, scanner.Err()
+}
+
+// resolveVersions filters and sorts tags into the requested [from, to] window, applying the
+// step policy (minor/patch/all) to pick which intermediate versions to keep
+func resolveVersions(tags []string, from, to, step string, includePrerelease bool) ([]string, error) {
+	var candidates []string
+	for _, tag := range tags {
+		v := tag
+		if !strings.HasPrefix(v, "v") {
+			v = "v" + v
+		}
+		if !semver.IsValid(v) {
+			continue
+		}
+		if !includePrerelease && semver.Prerelease(v) != "" {
+			continue
+		}
+		candidates = append(candidates, v)
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		return semver.Compare(candidates[i], candidates[j]) < 0
+	})
+
+	if to != "" && to != "latest" && !strings.HasPrefix(to, "v") {
+		to = "v" + to
+	}
+	if from != "" && !strings.HasPrefix(from, "v") {
+		from = "v" + from
+	}
+
+	var windowed []string
+	for _, v := range candidates {
+		if from != "" && semver.Compare(v, from) < 0 {
+			continue
+		}
+		if to != "" && to != "latest" && semver.Compare(v, to) > 0 {
+			continue
+		}
+		windowed = append(windowed, v)
+	}
+
+	return applyStep(windowed, step), nil
+}
+
+// applyStep reduces the windowed, sorted version list to one entry per step: every version for
+// "all", the last patch of every minor for "minor", or every version for "patch" (patch releases
+// are already the finest granularity we track)
+func applyStep(versions []string, step string) []string {
+	switch step {
+	case "all", "patch":
+		return versions
+	case "minor", "":
+		var result []string
+		seen := map[string]string{}
+		order := []string{}
+		for _, v := range versions {
+			minor := semver.MajorMinor(v)
+			if _, ok := seen[minor]; !ok {
+				order = append(order, minor)
+			}
+			seen[minor] = v
+		}
+		for _, minor := range order {
+			result = append(result, seen[minor])
+		}
+		return result
+	default:
+		return versions
+	}
+}
+
+func baseName(repoURL string) string {
+	parts := strings.Split(strings.TrimSuffix(repoURL, "/"), "/")
+	return parts[len(parts)-1]
+}
@@ -0,0 +1,171 @@
+// cmd/init.go
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+var (
+	initConfigFile    string
+	initKubeconfig    string
+	initForce         bool
+	initDryRun        bool
+	initTargetVersion string
+)
+
+var csvGVR = schema.GroupVersionResource{
+	Group:    "operators.coreos.com",
+	Version:  "v1alpha1",
+	Resource: "clusterserviceversions",
+}
+
+var initCmd = &cobra.Command{
+	Use:     "init",
+	Short:   "Scaffold a config.yaml from the current cluster",
+	GroupID: groupManagement,
+	Long: `Inspect the current cluster for installed operators and scaffold a working
+config.yaml with one upgrade path per detected operator, pre-filled with its
+current CSV version and commented-out placeholders for the remaining fields.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runInit()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(initCmd)
+
+	initCmd.Flags().StringVar(&initConfigFile, "config", "config.yaml", "path to write the scaffolded configuration file")
+	initCmd.Flags().StringVar(&initKubeconfig, "kubeconfig", "", "path to kubeconfig file, if not set, get KUBECONFIG from env, or ~/.kube/config")
+	initCmd.Flags().BoolVar(&initForce, "force", false, "overwrite config if it already exists")
+	initCmd.Flags().BoolVar(&initDryRun, "dry-run", false, "print the generated config to stdout instead of writing it")
+	initCmd.Flags().StringVar(&initTargetVersion, "target-version", "", "pre-fill a second version entry in every detected path with this target")
+}
+
+// detectedOperator is a minimal view of an installed operator, enough to scaffold a starter
+// upgrade path for it
+type detectedOperator struct {
+	name       string
+	currentCSV string
+	namespace  string
+}
+
+func runInit() error {
+	if !initDryRun && !initForce {
+		if _, err := os.Stat(initConfigFile); err == nil {
+			return fmt.Errorf("%s already exists, use --force to overwrite or --dry-run to preview", initConfigFile)
+		}
+	}
+
+	kubeconfig := initKubeconfig
+	if kubeconfig == "" {
+		kubeconfig = getKubeconfig()
+	}
+	k8sConfig, err := loadKubeConfig(kubeconfig)
+	if err != nil {
+		return fmt.Errorf("failed to load kubernetes config: %v", err)
+	}
+
+	client, err := dynamic.NewForConfig(k8sConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create kubernetes client: %v", err)
+	}
+
+	operators, err := detectOperators(context.Background(), client)
+	if err != nil {
+		return fmt.Errorf("failed to detect installed operators: %v", err)
+	}
+
+	out := renderConfig(operators, initTargetVersion)
+
+	if initDryRun {
+		_, err := os.Stdout.Write(out)
+		return err
+	}
+
+	if err := os.WriteFile(initConfigFile, out, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %v", initConfigFile, err)
+	}
+	fmt.Printf("wrote %s with %d detected operator(s)\n", initConfigFile, len(operators))
+	return nil
+}
+
+// detectOperators lists every ClusterServiceVersion in the cluster and returns one
+// detectedOperator per distinct package, keeping the first CSV listed for that package as its
+// current version
+func detectOperators(ctx context.Context, client dynamic.Interface) ([]detectedOperator, error) {
+	list, err := client.Resource(csvGVR).Namespace("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	var operators []detectedOperator
+	seen := map[string]bool{}
+	for _, item := range list.Items {
+		name := packageName(item)
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		operators = append(operators, detectedOperator{
+			name:       name,
+			currentCSV: item.GetName(),
+			namespace:  item.GetNamespace(),
+		})
+	}
+	return operators, nil
+}
+
+// packageName recovers the operator package name from a CSV's operators.coreos.com/package
+// label, falling back to the CSV name when the label is missing
+func packageName(csv unstructured.Unstructured) string {
+	if pkg, found, _ := unstructured.NestedString(csv.Object, "metadata", "labels", "operators.coreos.com/package"); found && pkg != "" {
+		return pkg
+	}
+	return csv.GetName()
+}
+
+// renderConfig builds a starter config.yaml as raw text (rather than through yaml.Marshal) so
+// it can carry explanatory comments and commented-out placeholder fields, which is the whole
+// point of a scaffold: it should be immediately runnable AND easy to hand-edit
+func renderConfig(operators []detectedOperator, targetVersion string) []byte {
+	var buf bytes.Buffer
+
+	buf.WriteString("# Scaffolded by `upgrade-test init` - review before running\n")
+	buf.WriteString("operatorConfig:\n")
+	buf.WriteString("  type: operatorhub\n\n")
+	buf.WriteString("upgradePaths:\n")
+
+	if len(operators) == 0 {
+		buf.WriteString("  # no operators were detected in the cluster, add entries manually\n")
+		return buf.Bytes()
+	}
+
+	for _, op := range operators {
+		fmt.Fprintf(&buf, "- name: %s\n", op.name)
+		buf.WriteString("  versions:\n")
+		fmt.Fprintf(&buf, "  - name: current\n")
+		fmt.Fprintf(&buf, "    bundleVersion: %s\n", op.currentCSV)
+		buf.WriteString("    # revision: \"\"\n")
+		buf.WriteString("    # testCommand: \"\"\n")
+		buf.WriteString("    # testSubPath: \"\"\n")
+		if targetVersion != "" {
+			fmt.Fprintf(&buf, "  - name: %s\n", targetVersion)
+			fmt.Fprintf(&buf, "    bundleVersion: %s\n", targetVersion)
+			buf.WriteString("    # revision: \"\"\n")
+			buf.WriteString("    # testCommand: \"\"\n")
+			buf.WriteString("    # testSubPath: \"\"\n")
+		}
+	}
+
+	return buf.Bytes()
+}
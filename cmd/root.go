@@ -6,13 +6,19 @@ import (
 	"github.com/spf13/cobra"
 )
 
+const (
+	groupManagement = "management"
+	groupDebug      = "debug"
+)
+
 var rootCmd = &cobra.Command{
 	Use:   "upgrade-test",
 	Short: "A tool for testing operator upgrades",
 	Long: `A tool for testing operator upgrades.
 It supports testing operator upgrades with different versions and paths.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		// Use the global instance that has the flags
+		// Preserve the historical default behavior of running with the global instance
+		// when no subcommand is given, e.g. "upgrade-test --config config.yaml"
 		return upgradeCommandInstance.Execute()
 	},
 }
@@ -20,6 +26,13 @@ It supports testing operator upgrades with different versions and paths.`,
 // Global upgrade command instance to share flags
 var upgradeCommandInstance *UpgradeCommand
 
+func init() {
+	rootCmd.AddGroup(
+		&cobra.Group{ID: groupManagement, Title: "Management Commands:"},
+		&cobra.Group{ID: groupDebug, Title: "Debug Commands:"},
+	)
+}
+
 // Execute adds all child commands to the root command and sets flags appropriately.
 func Execute() {
 	// Create a global instance and add flags to it
@@ -3,8 +3,11 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"go.uber.org/zap"
@@ -15,18 +18,27 @@ import (
 	"github.com/AlaudaDevops/upgrade-test/pkg/config"
 	"github.com/AlaudaDevops/upgrade-test/pkg/exec"
 	"github.com/AlaudaDevops/upgrade-test/pkg/operator"
+	operatorerrors "github.com/AlaudaDevops/upgrade-test/pkg/operator/errors"
+	"github.com/AlaudaDevops/upgrade-test/pkg/report"
 	"knative.dev/pkg/logging"
 )
 
 // UpgradeCommand represents the upgrade command implementation
 type UpgradeCommand struct {
-	configFile string
-	kubeconfig string
-	logLevel   string
-	workspace  string
-	logger     *zap.Logger
-	config     *config.Config
-	operator   operator.OperatorInterface
+	configFile  string
+	kubeconfig  string
+	logLevel    string
+	workspace   string
+	reportJUnit string
+	reportProm  string
+	reportDir   string
+	setValues   []string
+	cleanup     bool
+	logger      *zap.Logger
+	config      *config.Config
+	operator    operator.OperatorInterface
+	recorder    *report.Recorder
+	testReport  *report.UpgradeReport
 }
 
 // NewUpgradeCommand creates a new instance of UpgradeCommand
@@ -68,6 +80,11 @@ func (uc *UpgradeCommand) AddFlags(cmd *cobra.Command) {
 	cmd.Flags().StringVar(&uc.kubeconfig, "kubeconfig", "", "path to kubeconfig file, if not set, get KUBECONFIG from env, or ~/.kube/config")
 	cmd.Flags().StringVar(&uc.logLevel, "log-level", "info", "log level (debug, info, warn, error)")
 	cmd.Flags().StringVar(&uc.workspace, "workspace", "", "workspace for the operator")
+	cmd.Flags().StringVar(&uc.reportJUnit, "report-junit", "", "write a JUnit XML report of per-hop results to this path")
+	cmd.Flags().StringVar(&uc.reportProm, "report-prom", "", "write a Prometheus textfile-collector metrics file of per-hop results to this path")
+	cmd.Flags().StringVar(&uc.reportDir, "report-dir", "", "directory to collect per-version test artifacts (allure-results/junit.xml) and write an aggregated report.json, report.html and junit-combined.xml; the run fails if any collected test case failed")
+	cmd.Flags().StringArrayVar(&uc.setValues, "set", nil, "set a config template parameter, key=val (can be repeated), takes precedence over config.yaml's parameters block")
+	cmd.Flags().BoolVar(&uc.cleanup, "cleanup", false, "remove every Subscription/InstallPlan this run created or approved once all upgrade paths have completed")
 }
 
 // Execute runs the upgrade command
@@ -75,7 +92,11 @@ func (uc *UpgradeCommand) Execute() error {
 	kubeconfig := uc.getKubeconfig()
 
 	// Load configuration
-	cfg, err := config.LoadConfig(uc.configFile)
+	setOverrides, err := parseSetValues(uc.setValues)
+	if err != nil {
+		return fmt.Errorf("failed to parse --set values: %v", err)
+	}
+	cfg, err := config.LoadConfigWithOverrides(uc.configFile, setOverrides)
 	if err != nil {
 		return fmt.Errorf("failed to load config: %v", err)
 	}
@@ -85,6 +106,11 @@ func (uc *UpgradeCommand) Execute() error {
 		cfg.OperatorConfig.Workspace = uc.workspace
 	}
 
+	cfg.OperatorConfig.ConfigPath = uc.configFile
+	if cfg.OperatorConfig.RunID == "" {
+		cfg.OperatorConfig.RunID = fmt.Sprintf("run-%d", time.Now().Unix())
+	}
+
 	// Create logger with configured level
 	logger, err := uc.newLogger(cfg.LogLevel)
 	if err != nil {
@@ -107,28 +133,95 @@ func (uc *UpgradeCommand) Execute() error {
 	factory := operator.NewOperatorFactory()
 	op, err := factory.CreateOperator(operator.OperatorType(cfg.OperatorConfig.Type), operator.OperatorOptions{
 		Config:         k8sConfig,
-		Namespace:      cfg.OperatorConfig.Namespace,
-		Name:           cfg.OperatorConfig.Name,
 		OperatorConfig: cfg.OperatorConfig,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to create operator manager: %v", err)
 	}
 	uc.operator = op
+	uc.recorder = report.NewRecorder()
+	uc.testReport = report.NewUpgradeReport()
 
 	// Process upgrade paths
+	var processErr error
 	for _, path := range cfg.UpgradePaths {
 		if err := uc.process(ctx, path); err != nil {
 			if !cfg.Immediate {
 				logger.Error("failed to process upgrade path", zap.String("path", path.Name), zap.Error(err))
 				continue
 			}
-			return fmt.Errorf("failed to process upgrade path: %s, error: %v", path.Name, err)
+			processErr = fmt.Errorf("failed to process upgrade path: %s, error: %v", path.Name, err)
+			break
+		}
+	}
+
+	if err := uc.writeReports(); err != nil {
+		logger.Error("failed to write reports", zap.Error(err))
+	}
+
+	if uc.cleanup {
+		if cleaner, ok := uc.operator.(operator.ResourceCleaner); ok {
+			if err := cleaner.CleanupManagedResources(ctx); err != nil {
+				logger.Error("failed to clean up managed resources", zap.Error(err))
+			}
+		} else {
+			logger.Warn("cleanup requested but operator does not support it, ignoring")
+		}
+	}
+
+	if processErr == nil && uc.testReport.HasFailures() {
+		processErr = fmt.Errorf("upgrade tests reported failing test cases")
+	}
+
+	return processErr
+}
+
+// writeReports exports the recorded hop results as a JUnit report and/or a Prometheus textfile,
+// whichever of --report-junit / --report-prom were set, and, when --report-dir is set, writes the
+// aggregated test report there as report.json, report.html and junit-combined.xml
+func (uc *UpgradeCommand) writeReports() error {
+	if uc.reportJUnit != "" {
+		if err := uc.recorder.WriteJUnit(uc.reportJUnit); err != nil {
+			return fmt.Errorf("failed to write junit report: %v", err)
+		}
+	}
+	if uc.reportProm != "" {
+		if err := uc.recorder.WritePrometheus(uc.reportProm); err != nil {
+			return fmt.Errorf("failed to write prometheus report: %v", err)
+		}
+	}
+	if uc.reportDir != "" {
+		if err := uc.testReport.WriteJSON(filepath.Join(uc.reportDir, "report.json")); err != nil {
+			return fmt.Errorf("failed to write test report json: %v", err)
+		}
+		if err := uc.testReport.WriteHTML(filepath.Join(uc.reportDir, "report.html")); err != nil {
+			return fmt.Errorf("failed to write test report html: %v", err)
+		}
+		if err := uc.testReport.WriteCombinedJUnit(filepath.Join(uc.reportDir, "junit-combined.xml")); err != nil {
+			return fmt.Errorf("failed to write combined junit report: %v", err)
 		}
 	}
 	return nil
 }
 
+// parseSetValues parses a list of --set key=val flags into a map, erroring on any entry missing
+// the "=" separator
+func parseSetValues(values []string) (map[string]string, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	overrides := make(map[string]string, len(values))
+	for _, v := range values {
+		key, val, ok := strings.Cut(v, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid --set value %q, expected key=val", v)
+		}
+		overrides[key] = val
+	}
+	return overrides, nil
+}
+
 // getKubeconfig returns the kubeconfig path
 func (uc *UpgradeCommand) getKubeconfig() string {
 	if uc.kubeconfig == "" {
@@ -187,38 +280,219 @@ func (uc *UpgradeCommand) process(ctx context.Context, path config.UpgradePath)
 	logger.Infow("==> processing upgrade path", "path", path.Name)
 
 	for index, version := range path.Versions {
-		logger.Infow("deploying operator version", "version", version.Name)
+		skip, err := uc.processHop(ctx, path, version, index)
+		if err != nil {
+			return err
+		}
+		if skip {
+			continue
+		}
+	}
+
+	logger.Infow("==> upgrade path completed", "path", path.Name)
+	return nil
+}
+
+// processHop deploys and tests a single version within an upgrade path. It returns skip=true
+// when preflight reported the hop as already satisfied, or when path.FailForward absorbed a
+// terminal failure (per operatorerrors.IsFatal), and the path should move on to the next version
+// without treating it as an error.
+func (uc *UpgradeCommand) processHop(ctx context.Context, path config.UpgradePath, version config.Version, index int) (bool, error) {
+	logger := logging.FromContext(ctx)
+	logger.Infow("deploying operator version", "version", version.Name)
+
+	hopStart := time.Now()
+	var passed, skipped bool
+	var hopErr error
+	defer func() {
+		result := report.HopResult{
+			Path:     path.Name,
+			Version:  version.Name,
+			Passed:   passed,
+			Skipped:  skipped,
+			Err:      hopErr,
+			Duration: time.Since(hopStart),
+		}
+		if reporter, ok := uc.operator.(report.TimingReporter); ok {
+			result.HopTiming = reporter.LastHopTiming()
+		}
+		if uc.recorder != nil {
+			uc.recorder.Record(result)
+		}
+	}()
+
+	// Ephemeral namespace hops run in their own generated namespace so hops don't
+	// accumulate state between each other; torn down once the hop is done
+	ephemeralNamespace := ""
+	if path.EphemeralNamespace {
+		if nsOp, ok := uc.operator.(operator.EphemeralNamespaceOperator); ok {
+			ephemeralNamespace = fmt.Sprintf("%s-hop-%d", uc.config.OperatorConfig.Namespace, index)
+			if err := nsOp.UseNamespace(ctx, ephemeralNamespace); err != nil {
+				return false, fmt.Errorf("failed to switch to ephemeral namespace %s: %v", ephemeralNamespace, err)
+			}
+			defer func() {
+				if err := nsOp.TeardownNamespace(ctx, ephemeralNamespace); err != nil {
+					logger.Warnw("failed to tear down ephemeral namespace", "namespace", ephemeralNamespace, "error", err)
+				}
+			}()
+		} else {
+			logger.Warnw("ephemeralNamespace requested but operator does not support it, ignoring", "path", path.Name)
+		}
+	}
+
+	// Preflight gates this hop on the state of the currently installed CSV/Subscription before
+	// UpgradeOperator runs, e.g. a minimum previous version or required upgrade-gate annotation
+	if preflightOp, ok := uc.operator.(operator.PreflightOperator); ok {
+		var prev config.Version
+		if index > 0 {
+			prev = path.Versions[index-1]
+		}
 
-		// Install artifact version
-		if err := uc.operator.UpgradeOperator(ctx, version); err != nil {
-			return fmt.Errorf("failed to prepare operator: %v", err)
+		rep, err := preflightOp.Preflight(ctx, prev, version)
+		if err != nil {
+			hopErr = fmt.Errorf("failed to evaluate preflight preconditions: %v", err)
+			return false, hopErr
 		}
+		logger.Infow("preflight report", "version", version.Name, "satisfied", rep.Satisfied, "skipped", rep.Skipped, "reason", rep.Reason)
 
-		// Determine test command
-		testCommand := "REPO=allure make upgrade"
-		if index == 0 {
-			testCommand = "REPO=allure make prepare"
+		if rep.Skipped {
+			skipped = true
+			return true, nil
 		}
-		if version.TestCommand != "" {
-			testCommand = version.TestCommand
+		if !rep.Satisfied {
+			hopErr = fmt.Errorf("preflight preconditions not satisfied for version %s: %s", version.Name, rep.Reason)
+			return false, hopErr
 		}
+	}
 
-		workspace := uc.config.OperatorConfig.Workspace
-		if version.TestSubPath != "" {
-			workspace = fmt.Sprintf("%s/%s", uc.config.OperatorConfig.Workspace, version.TestSubPath)
+	// Install artifact version
+	if err := uc.operator.UpgradeOperator(ctx, version); err != nil {
+		if path.FailForward && operatorerrors.IsFatal(err) {
+			logger.Warnw("version hit a terminal failure, skipping to next version",
+				"version", version.Name, "error", err)
+			skipped = true
+			return true, nil
 		}
+		hopErr = fmt.Errorf("failed to prepare operator: %v", err)
+		return false, hopErr
+	}
+
+	// Determine test command
+	testCommand := "REPO=allure make upgrade"
+	if index == 0 {
+		testCommand = "REPO=allure make prepare"
+	}
+	if version.TestCommand != "" {
+		testCommand = version.TestCommand
+	}
+
+	workspace := uc.config.OperatorConfig.Workspace
+	if version.TestSubPath != "" {
+		workspace = fmt.Sprintf("%s/%s", uc.config.OperatorConfig.Workspace, version.TestSubPath)
+	}
+
+	// Execute test commands
+	if err := uc.execCommand(ctx, workspace, testCommand); err != nil {
+		hopErr = fmt.Errorf("failed to execute test command: %v", err)
+		return false, hopErr
+	}
+
+	if err := uc.collectTestResults(ctx, path, version, workspace); err != nil {
+		hopErr = fmt.Errorf("failed to collect test results: %v", err)
+		return false, hopErr
+	}
 
-		// Execute test commands
-		if err := uc.execCommand(ctx,
-			workspace,
-			testCommand); err != nil {
-			return fmt.Errorf("failed to execute test command: %v", err)
+	passed = true
+	logger.Info("upgrade test passed", "version", version.Name)
+	return false, nil
+}
+
+// collectTestResults parses the allure-results/junit.xml artifacts the test command left behind
+// in workspace and appends them to uc.testReport, annotated with the CSV/channel the operator
+// resolved for this hop. When --report-dir is set, the raw artifacts are also archived under
+// reportDir/<path>/<version>/ so they survive the workspace being reused by the next hop.
+func (uc *UpgradeCommand) collectTestResults(ctx context.Context, path config.UpgradePath, version config.Version, workspace string) error {
+	logger := logging.FromContext(ctx)
+
+	results, err := report.CollectTestResults(workspace)
+	if err != nil {
+		return err
+	}
+
+	versionReport := report.VersionTestReport{
+		Path:          path.Name,
+		Version:       version.Name,
+		BundleVersion: version.BundleVersion,
+		Results:       results,
+	}
+	if resolver, ok := uc.operator.(report.VersionResolver); ok {
+		versionReport.CSV, versionReport.Channel = resolver.LastResolvedVersion()
+	}
+	uc.testReport.Add(versionReport)
+
+	if len(results) == 0 {
+		logger.Infow("no allure-results/junit.xml found in workspace, skipping artifact archival", "workspace", workspace)
+		return nil
+	}
+
+	if uc.reportDir == "" {
+		return nil
+	}
+
+	dest := filepath.Join(uc.reportDir, path.Name, version.Name)
+	for _, name := range []string{"allure-results", "junit.xml"} {
+		src := filepath.Join(workspace, name)
+		if _, err := os.Stat(src); err != nil {
+			continue
 		}
+		if err := copyPath(src, filepath.Join(dest, name)); err != nil {
+			return fmt.Errorf("failed to archive %s: %v", src, err)
+		}
+	}
 
-		logger.Info("upgrade test passed", "version", version.Name)
+	return nil
+}
+
+// copyPath copies src to dst, recursing into directories; used to archive test artifacts into
+// --report-dir without disturbing the originals the next hop's test command may reuse
+func copyPath(src, dst string) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
 	}
 
-	logger.Infow("==> upgrade path completed", "path", path.Name)
+	if !info.IsDir() {
+		if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+			return err
+		}
+		in, err := os.Open(src)
+		if err != nil {
+			return err
+		}
+		defer in.Close()
+
+		out, err := os.Create(dst)
+		if err != nil {
+			return err
+		}
+		defer out.Close()
+
+		_, err = io.Copy(out, in)
+		return err
+	}
+
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dst, 0755); err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if err := copyPath(filepath.Join(src, entry.Name()), filepath.Join(dst, entry.Name())); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -24,10 +24,17 @@ type Config struct {
 
 	// operatorConfig is the configuration for the operator
 	OperatorConfig OperatorConfig `yaml:"operatorConfig,omitempty"`
+
+	// parameters are inline key/value pairs available to every {{ .Params.foo }} / ${FOO}
+	// placeholder in the rest of this config, merged over paramFiles
+	Parameters map[string]string `yaml:"parameters,omitempty"`
+	// paramFiles are paths to YAML files of key/value pairs merged in order, then overlaid by
+	// parameters, then by any --set key=val flags passed on the command line
+	ParamFiles []string `yaml:"paramFiles,omitempty"`
 }
 
 type OperatorConfig struct {
-	// type is the type of the operator, support operatorhub and local, default is operatorhub
+	// type is the type of the operator, support operatorhub, local and helm, default is operatorhub
 	Type string `yaml:"type,omitempty"`
 
 	// artifact is the name of the artifact to use
@@ -49,6 +56,53 @@ type OperatorConfig struct {
 
 	// command for running the operator, just for local operator, default is "make deploy"
 	Command string `yaml:"command,omitempty"`
+
+	// operatorGroupMode is the OperatorGroup install mode to ensure before creating the
+	// Subscription: AllNamespaces, OwnNamespace or SingleNamespace. Default is OwnNamespace
+	OperatorGroupMode string `yaml:"operatorGroupMode,omitempty"`
+
+	// healthTimeout is the timeout to use when verifying the CSV and its Deployments are healthy
+	// after an upgrade, default is the same as timeout
+	HealthTimeout time.Duration `yaml:"healthTimeout,omitempty"`
+
+	// approval is the default Subscription installPlanApproval to use for versions that don't set
+	// their own: "Automatic" or "Manual". Default is "Manual"
+	Approval string `yaml:"approval,omitempty"`
+
+	// resolutionGracePeriod bounds how long to wait for OLM to resolve a Subscription stuck
+	// reporting ResolutionFailed/ConstraintsNotSatisfiable (e.g. right after a catalog refresh)
+	// before intervening, default is 30 seconds
+	ResolutionGracePeriod time.Duration `yaml:"resolutionGracePeriod,omitempty"`
+
+	// runID identifies this test run, stamped onto managed Subscriptions/InstallPlans for
+	// traceability and cleanup; the upgrade command fills this in with a generated value if left
+	// unset
+	RunID string `yaml:"runID,omitempty"`
+	// configPath is the path to the config file this run was loaded from, stamped onto managed
+	// Subscriptions/InstallPlans for traceability; the upgrade command fills this in automatically
+	ConfigPath string `yaml:"-"`
+}
+
+// GitConfig represents the configuration used to clone and build a git repository
+type GitConfig struct {
+	// revision is the branch, tag or commit to checkout
+	Revision string `yaml:"revision,omitempty"`
+
+	// tls carries the TLS options used when talking to a private git server
+	TLS TLSConfig `yaml:"tls,omitempty"`
+}
+
+// TLSConfig carries the CA bundle and client certificate options used to trust
+// a private git server (e.g. an internal GitLab/Gitea/GHE instance with a self-signed root)
+type TLSConfig struct {
+	// caBundle is the CA certificate trusted to verify the git server, either inline PEM or a path to a PEM file
+	CABundle string `yaml:"caBundle,omitempty"`
+	// insecureSkipVerify disables TLS verification of the git server, should only be used for testing
+	InsecureSkipVerify bool `yaml:"insecureSkipVerify,omitempty"`
+	// clientCert is the path to a client certificate used for mTLS
+	ClientCert string `yaml:"clientCert,omitempty"`
+	// clientKey is the path to a client key used for mTLS
+	ClientKey string `yaml:"clientKey,omitempty"`
 }
 
 // UpgradePath represents a single upgrade path
@@ -57,6 +111,13 @@ type UpgradePath struct {
 	Name string `yaml:"name,omitempty"`
 	// versions is the list of versions to test
 	Versions []Version `yaml:"versions,omitempty"`
+	// failForward skips to the next version in Versions when a version hits a terminal,
+	// non-retryable failure (per operatorerrors.IsFatal, e.g. a bundle unpack failure or a CSV
+	// that reaches a terminal Failed reason), instead of aborting the whole path
+	FailForward bool `yaml:"failForward,omitempty"`
+	// ephemeralNamespace runs each entry in Versions in its own generated namespace, torn
+	// down afterward, so hops don't accumulate state between each other
+	EphemeralNamespace bool `yaml:"ephemeralNamespace,omitempty"`
 }
 
 // Version represents a single version in the upgrade path
@@ -71,21 +132,110 @@ type Version struct {
 	TestSubPath string `yaml:"testSubPath,omitempty"`
 	// revision is the revision to use for the version
 	Channel string `yaml:"channel,omitempty"`
+	// approval is the Subscription installPlanApproval to use for this version: "Automatic" or
+	// "Manual", default is OperatorConfig.Approval
+	Approval string `yaml:"approval,omitempty"`
+
+	// allowedCSVVersions gates Manual install plan approval: every CSV listed on the install
+	// plan must have a spec.version matching one of these, either an exact version ("1.2.3") or
+	// a major.minor prefix ("1.2") matching any patch release. Empty means no gating.
+	AllowedCSVVersions []string `yaml:"allowedCSVVersions,omitempty"`
+
+	// fallbackCSV and fallbackChannel are used to recreate the Subscription if it's still
+	// reporting ResolutionFailed/ConstraintsNotSatisfiable after OperatorConfig.ResolutionGracePeriod
+	// has elapsed. Empty means no fallback: the hop fails with the unsatisfiable constraint instead.
+	FallbackCSV     string `yaml:"fallbackCSV,omitempty"`
+	FallbackChannel string `yaml:"fallbackChannel,omitempty"`
+
+	// chartRepo is the Helm repository URL the chart is resolved from, only used when
+	// OperatorConfig.Type is "helm"
+	ChartRepo string `yaml:"chartRepo,omitempty"`
+	// chartName is the name of the chart to install/upgrade, only used when OperatorConfig.Type
+	// is "helm"
+	ChartName string `yaml:"chartName,omitempty"`
+	// chartVersion is the chart version to resolve from ChartRepo, only used when
+	// OperatorConfig.Type is "helm"
+	ChartVersion string `yaml:"chartVersion,omitempty"`
+	// values are inline chart values, merged over ValuesFiles, only used when
+	// OperatorConfig.Type is "helm"
+	Values map[string]interface{} `yaml:"values,omitempty"`
+	// valuesFiles are paths to YAML values files applied in order before Values, only used when
+	// OperatorConfig.Type is "helm"
+	ValuesFiles []string `yaml:"valuesFiles,omitempty"`
+
+	// preconditions gates this hop on the state of the currently installed CSV/Subscription,
+	// checked by Preflight before UpgradeOperator runs
+	Preconditions Preconditions `yaml:"preconditions,omitempty"`
+
+	// overrides re-renders this same Version with a different set of field values depending on
+	// the resolved value of a parameter, keyed "paramName=value" (e.g. "cloud=aws"); the first
+	// key whose parameter resolves to the given value is applied over this Version's own fields
+	Overrides map[string]VersionOverride `yaml:"overrides,omitempty"`
+}
+
+// VersionOverride carries the subset of Version fields that commonly differ between overrides,
+// e.g. a different registry host or chart values file for one cloud vs another
+type VersionOverride struct {
+	BundleVersion string                 `yaml:"bundleVersion,omitempty"`
+	Channel       string                 `yaml:"channel,omitempty"`
+	ChartRepo     string                 `yaml:"chartRepo,omitempty"`
+	ChartName     string                 `yaml:"chartName,omitempty"`
+	ChartVersion  string                 `yaml:"chartVersion,omitempty"`
+	Values        map[string]interface{} `yaml:"values,omitempty"`
+	ValuesFiles   []string               `yaml:"valuesFiles,omitempty"`
+}
+
+// Preconditions describes upgradeable preconditions checked against the currently installed CSV
+// and Subscription before a hop's UpgradeOperator runs, in the style of the Cluster Version
+// Operator's upgradeable preconditions
+type Preconditions struct {
+	// minPreviousVersion is the minimum semver version the currently installed CSV must satisfy
+	MinPreviousVersion string `yaml:"minPreviousVersion,omitempty"`
+	// allowedChannelsFrom restricts this hop to running only when the current Subscription's
+	// channel is one of these
+	AllowedChannelsFrom []string `yaml:"allowedChannelsFrom,omitempty"`
+	// requiredCSVAnnotations lists annotation keys that must be present on the currently
+	// installed CSV (e.g. "olm.skipRange", or an operator-specific upgrade-gate key)
+	RequiredCSVAnnotations []string `yaml:"requiredCSVAnnotations,omitempty"`
+	// skipIfCSVPresent short-circuits this hop as already satisfied when the currently installed
+	// CSV's name already matches this value
+	SkipIfCSVPresent string `yaml:"skipIfCSVPresent,omitempty"`
 }
 
 // LoadConfig loads the configuration from a YAML file
 func LoadConfig(path string) (*Config, error) {
+	return LoadConfigWithOverrides(path, nil)
+}
+
+// LoadConfigWithOverrides loads the configuration from a YAML file, then resolves parameters and
+// expands {{ .Params.foo }} / ${FOO} placeholders across every string field of the result.
+// Parameters are merged in ascending precedence: config.paramFiles (in order), then
+// config.parameters, then setOverrides (typically from --set key=val flags). Applicable
+// Version.Overrides entries are then merged on top of each matching Version. Returns a
+// descriptive error listing any placeholder that could not be resolved.
+func LoadConfigWithOverrides(path string, setOverrides map[string]string) (*Config, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
 
-	var config Config
-	if err := yaml.Unmarshal(data, &config); err != nil {
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, err
+	}
+
+	params, err := resolveParams(cfg.ParamFiles, cfg.Parameters, setOverrides)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := renderConfig(&cfg, params); err != nil {
 		return nil, err
 	}
 
-	return defaultConfig(&config), nil
+	applyVersionOverrides(&cfg, params)
+
+	return defaultConfig(&cfg), nil
 }
 
 func defaultConfig(config *Config) *Config {
@@ -108,5 +258,21 @@ func defaultConfig(config *Config) *Config {
 		config.OperatorConfig.Timeout = 10 * time.Minute
 	}
 
+	if config.OperatorConfig.OperatorGroupMode == "" {
+		config.OperatorConfig.OperatorGroupMode = "OwnNamespace"
+	}
+
+	if config.OperatorConfig.HealthTimeout == 0 {
+		config.OperatorConfig.HealthTimeout = config.OperatorConfig.Timeout
+	}
+
+	if config.OperatorConfig.Approval == "" {
+		config.OperatorConfig.Approval = "Manual"
+	}
+
+	if config.OperatorConfig.ResolutionGracePeriod == 0 {
+		config.OperatorConfig.ResolutionGracePeriod = 30 * time.Second
+	}
+
 	return config
 }
@@ -0,0 +1,339 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/Masterminds/sprig/v3"
+	"gopkg.in/yaml.v2"
+)
+
+// maxTemplatePasses bounds how many times a single string is re-rendered so a parameter value
+// that itself references another parameter (recursion) gets resolved, without risking an
+// infinite loop on a circular reference
+const maxTemplatePasses = 5
+
+var paramRefPattern = regexp.MustCompile(`\.Params\.([A-Za-z0-9_]+)`)
+
+// resolveParams builds the final parameter map for template expansion: paramFiles are merged in
+// order, then inline is overlaid on top, then overrides (typically --set key=val flags), each
+// taking precedence over the last. Parameter values that reference other parameters are
+// resolved against the merged map before being returned.
+func resolveParams(paramFiles []string, inline map[string]string, overrides map[string]string) (map[string]string, error) {
+	params := map[string]string{}
+
+	for _, path := range paramFiles {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read param file %s: %v", path, err)
+		}
+
+		var fileParams map[string]string
+		if err := yaml.Unmarshal(data, &fileParams); err != nil {
+			return nil, fmt.Errorf("failed to parse param file %s: %v", path, err)
+		}
+		for k, v := range fileParams {
+			params[k] = v
+		}
+	}
+
+	for k, v := range inline {
+		params[k] = v
+	}
+	for k, v := range overrides {
+		params[k] = v
+	}
+
+	var missing []string
+	for k, v := range params {
+		rendered, miss, err := renderUntilStable(v, params, maxTemplatePasses)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render parameter %q: %v", k, err)
+		}
+		params[k] = rendered
+		missing = append(missing, miss...)
+	}
+
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("unresolved parameters: %s", strings.Join(dedupSorted(missing), ", "))
+	}
+
+	return params, nil
+}
+
+// renderConfig expands {{ .Params.foo }} / ${FOO} placeholders across every string field of cfg,
+// recursing into its nested structs, slices and string-keyed maps
+func renderConfig(cfg *Config, params map[string]string) error {
+	var missing []string
+
+	err := walkStrings(reflect.ValueOf(cfg).Elem(), func(s string) (string, error) {
+		rendered, miss, err := renderUntilStable(s, params, maxTemplatePasses)
+		if err != nil {
+			return "", err
+		}
+		missing = append(missing, miss...)
+		return rendered, nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to render config template: %v", err)
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("unresolved parameters: %s", strings.Join(dedupSorted(missing), ", "))
+	}
+
+	return nil
+}
+
+// applyVersionOverrides merges each Version's Overrides entry whose "paramName=value" key
+// matches the resolved parameters on top of that Version's own fields, so the same path
+// definition can render differently for e.g. cloud=aws vs cloud=azure. Keys are evaluated in
+// sorted order and only the first match is applied.
+func applyVersionOverrides(cfg *Config, params map[string]string) {
+	for pi := range cfg.UpgradePaths {
+		versions := cfg.UpgradePaths[pi].Versions
+		for vi := range versions {
+			version := &versions[vi]
+			if len(version.Overrides) == 0 {
+				continue
+			}
+
+			keys := make([]string, 0, len(version.Overrides))
+			for k := range version.Overrides {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+
+			for _, key := range keys {
+				name, value, ok := strings.Cut(key, "=")
+				if !ok || params[name] != value {
+					continue
+				}
+
+				override := version.Overrides[key]
+				if override.BundleVersion != "" {
+					version.BundleVersion = override.BundleVersion
+				}
+				if override.Channel != "" {
+					version.Channel = override.Channel
+				}
+				if override.ChartRepo != "" {
+					version.ChartRepo = override.ChartRepo
+				}
+				if override.ChartName != "" {
+					version.ChartName = override.ChartName
+				}
+				if override.ChartVersion != "" {
+					version.ChartVersion = override.ChartVersion
+				}
+				if override.Values != nil {
+					version.Values = override.Values
+				}
+				if override.ValuesFiles != nil {
+					version.ValuesFiles = override.ValuesFiles
+				}
+				break
+			}
+		}
+	}
+}
+
+// walkStrings recursively visits every string reachable from v (through structs, slices, arrays,
+// string-keyed maps and interface{} values such as a Helm chart's map[string]interface{} values),
+// replacing each with fn's result
+func walkStrings(v reflect.Value, fn func(string) (string, error)) error {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return nil
+		}
+		return walkStrings(v.Elem(), fn)
+
+	case reflect.Interface:
+		// Unwrap to the dynamic type (e.g. a slice element or struct field typed interface{})
+		// and write the rendered result back if this Value came from somewhere settable
+		rendered, err := renderValue(v, fn)
+		if err != nil {
+			return err
+		}
+		if rendered.IsValid() && v.CanSet() {
+			v.Set(rendered)
+		}
+
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Field(i)
+			if !field.CanSet() {
+				continue
+			}
+			if err := walkStrings(field, fn); err != nil {
+				return err
+			}
+		}
+
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := walkStrings(v.Index(i), fn); err != nil {
+				return err
+			}
+		}
+
+	case reflect.Map:
+		for _, key := range v.MapKeys() {
+			rendered, err := renderValue(v.MapIndex(key), fn)
+			if err != nil {
+				return err
+			}
+			if rendered.IsValid() {
+				v.SetMapIndex(key, rendered)
+			}
+		}
+
+	case reflect.String:
+		rendered, err := fn(v.String())
+		if err != nil {
+			return err
+		}
+		if v.CanSet() {
+			v.SetString(rendered)
+		}
+	}
+
+	return nil
+}
+
+// renderValue computes the rendered replacement for v, unwrapping an Interface kind to its
+// dynamic type first - needed for map[string]interface{} values and []interface{} elements (e.g.
+// Version.Values / VersionOverride.Values, the Helm chart values map), whose reflect.Kind() is
+// Interface rather than String/Struct/Slice/Map/Ptr. Returns an invalid Value when v needs no
+// change (nil interface, or a kind walkStrings doesn't render, e.g. a bool or int).
+func renderValue(v reflect.Value, fn func(string) (string, error)) (reflect.Value, error) {
+	if v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return reflect.Value{}, nil
+		}
+		return renderValue(v.Elem(), fn)
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		rendered, err := fn(v.String())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(rendered), nil
+	case reflect.Struct, reflect.Slice, reflect.Array, reflect.Map, reflect.Ptr:
+		tmp := reflect.New(v.Type()).Elem()
+		tmp.Set(v)
+		if err := walkStrings(tmp, fn); err != nil {
+			return reflect.Value{}, err
+		}
+		return tmp, nil
+	default:
+		return reflect.Value{}, nil
+	}
+}
+
+// renderUntilStable repeatedly renders s as a Go template against params (so a parameter value
+// that references another parameter gets resolved too), then expands any ${FOO} shell-style
+// placeholders, falling back to the OS environment. It returns every .Params.foo or ${FOO}
+// reference it could not resolve.
+func renderUntilStable(s string, params map[string]string, maxPasses int) (string, []string, error) {
+	var missing []string
+
+	for i := 0; i < maxPasses; i++ {
+		missing = append(missing, missingParamRefs(s, params)...)
+
+		next, err := renderTemplate(s, params)
+		if err != nil {
+			return "", nil, err
+		}
+		if next == s {
+			break
+		}
+		s = next
+	}
+
+	expanded, envMissing := expandEnv(s, params)
+	missing = append(missing, envMissing...)
+
+	return expanded, missing, nil
+}
+
+// renderTemplate executes s as a text/template with sprig's function map and .Params bound to
+// params. A reference to a parameter that doesn't exist renders as "<no value>", text/template's
+// default output for a missing map key; missingParamRefs is what actually flags it as
+// unresolved.
+func renderTemplate(s string, params map[string]string) (string, error) {
+	if !strings.Contains(s, "{{") {
+		return s, nil
+	}
+
+	tmpl, err := template.New("value").Funcs(sprig.TxtFuncMap()).Parse(s)
+	if err != nil {
+		return "", fmt.Errorf("invalid template %q: %v", s, err)
+	}
+
+	var buf bytes.Buffer
+	data := struct{ Params map[string]string }{Params: params}
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render template %q: %v", s, err)
+	}
+
+	return buf.String(), nil
+}
+
+// missingParamRefs returns every {{ .Params.foo }} reference in s whose name isn't in params
+func missingParamRefs(s string, params map[string]string) []string {
+	var missing []string
+	for _, match := range paramRefPattern.FindAllStringSubmatch(s, -1) {
+		name := match[1]
+		if _, ok := params[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
+
+// expandEnv expands ${FOO} (and $FOO) placeholders in s, looking up FOO in params first and
+// falling back to the OS environment. "$$" is an escape for a literal "$". Names resolved by
+// neither params nor the environment are returned as unresolved.
+func expandEnv(s string, params map[string]string) (string, []string) {
+	const sentinel = "\x00ESCAPED_DOLLAR\x00"
+	escaped := strings.ReplaceAll(s, "$$", sentinel)
+
+	var missing []string
+	expanded := os.Expand(escaped, func(name string) string {
+		if v, ok := params[name]; ok {
+			return v
+		}
+		if v, ok := os.LookupEnv(name); ok {
+			return v
+		}
+		missing = append(missing, name)
+		return ""
+	})
+
+	return strings.ReplaceAll(expanded, sentinel, "$"), missing
+}
+
+// dedupSorted returns values with duplicates removed, sorted for a stable error message
+func dedupSorted(values []string) []string {
+	seen := map[string]struct{}{}
+	var out []string
+	for _, v := range values {
+		if _, ok := seen[v]; ok {
+			continue
+		}
+		seen[v] = struct{}{}
+		out = append(out, v)
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,233 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+func TestResolveParams_Recursion(t *testing.T) {
+	params, err := resolveParams(nil, map[string]string{
+		"base":    "v1",
+		"derived": "{{ .Params.base }}-suffix",
+		"chained": "{{ .Params.derived }}-final",
+	}, nil)
+	if err != nil {
+		t.Fatalf("resolveParams() error = %v", err)
+	}
+	if got, want := params["derived"], "v1-suffix"; got != want {
+		t.Errorf("derived = %q, want %q", got, want)
+	}
+	if got, want := params["chained"], "v1-suffix-final"; got != want {
+		t.Errorf("chained = %q, want %q", got, want)
+	}
+}
+
+// TestResolveParams_CircularReferenceDoesNotHang asserts maxTemplatePasses bounds rendering of a
+// circular parameter reference instead of looping forever; it doesn't matter that the result is
+// left unresolved, only that resolveParams returns at all.
+func TestResolveParams_CircularReferenceDoesNotHang(t *testing.T) {
+	if _, err := resolveParams(nil, map[string]string{
+		"a": "{{ .Params.b }}",
+		"b": "{{ .Params.a }}",
+	}, nil); err != nil {
+		t.Fatalf("resolveParams() error = %v, want nil (both names exist, just unstable)", err)
+	}
+}
+
+func TestResolveParams_UnresolvedParamIsReported(t *testing.T) {
+	_, err := resolveParams(nil, map[string]string{"foo": "{{ .Params.missing }}"}, nil)
+	if err == nil {
+		t.Fatal("resolveParams() error = nil, want an unresolved parameter error")
+	}
+}
+
+// TestResolveParams_Precedence asserts the documented override order: paramFiles, then inline,
+// then overrides, each taking precedence over the last.
+func TestResolveParams_Precedence(t *testing.T) {
+	dir := t.TempDir()
+	paramFile := filepath.Join(dir, "params.yaml")
+	if err := os.WriteFile(paramFile, []byte("key: from-file\nfileOnly: from-file\n"), 0644); err != nil {
+		t.Fatalf("failed to write param file: %v", err)
+	}
+
+	params, err := resolveParams(
+		[]string{paramFile},
+		map[string]string{"key": "from-inline"},
+		map[string]string{"key": "from-override"},
+	)
+	if err != nil {
+		t.Fatalf("resolveParams() error = %v", err)
+	}
+	if got, want := params["key"], "from-override"; got != want {
+		t.Errorf("key = %q, want %q", got, want)
+	}
+	if got, want := params["fileOnly"], "from-file"; got != want {
+		t.Errorf("fileOnly = %q, want %q", got, want)
+	}
+}
+
+func TestExpandEnv_EscapesLiteralDollar(t *testing.T) {
+	expanded, missing := expandEnv("price: $$5 for ${FOO}", map[string]string{"FOO": "bar"})
+	if got, want := expanded, "price: $5 for bar"; got != want {
+		t.Errorf("expandEnv() = %q, want %q", got, want)
+	}
+	if len(missing) != 0 {
+		t.Errorf("missing = %v, want none", missing)
+	}
+}
+
+func TestExpandEnv_FallsBackToOSEnvironment(t *testing.T) {
+	t.Setenv("UPGRADE_TEST_TEMPLATE_VAR", "from-env")
+
+	expanded, missing := expandEnv("${UPGRADE_TEST_TEMPLATE_VAR}", nil)
+	if got, want := expanded, "from-env"; got != want {
+		t.Errorf("expandEnv() = %q, want %q", got, want)
+	}
+	if len(missing) != 0 {
+		t.Errorf("missing = %v, want none", missing)
+	}
+}
+
+func TestExpandEnv_UnresolvedNameIsReported(t *testing.T) {
+	_, missing := expandEnv("${UPGRADE_TEST_TEMPLATE_UNSET}", nil)
+	if len(missing) != 1 || missing[0] != "UPGRADE_TEST_TEMPLATE_UNSET" {
+		t.Errorf("missing = %v, want [UPGRADE_TEST_TEMPLATE_UNSET]", missing)
+	}
+}
+
+func TestRenderUntilStable_Recursion(t *testing.T) {
+	params := map[string]string{"base": "v1", "derived": "{{ .Params.base }}-suffix"}
+	rendered, missing, err := renderUntilStable("{{ .Params.derived }}/${base}", params, maxTemplatePasses)
+	if err != nil {
+		t.Fatalf("renderUntilStable() error = %v", err)
+	}
+	if len(missing) != 0 {
+		t.Errorf("missing = %v, want none", missing)
+	}
+	if got, want := rendered, "v1-suffix/v1"; got != want {
+		t.Errorf("rendered = %q, want %q", got, want)
+	}
+}
+
+// TestApplyVersionOverrides_Precedence asserts that when several override keys match, they are
+// evaluated in sorted key order and only the first matching key is applied.
+func TestApplyVersionOverrides_Precedence(t *testing.T) {
+	cfg := &Config{
+		UpgradePaths: []UpgradePath{{
+			Versions: []Version{{
+				BundleVersion: "1.0.0",
+				Channel:       "stable",
+				Overrides: map[string]VersionOverride{
+					"region=us":   {BundleVersion: "3.0.0", Channel: "us-stable"},
+					"cloud=azure": {BundleVersion: "2.0.0"},
+					"cloud=aws":   {BundleVersion: "1.5.0", Channel: "aws-stable"},
+				},
+			}},
+		}},
+	}
+
+	applyVersionOverrides(cfg, map[string]string{"cloud": "aws", "region": "us"})
+
+	version := cfg.UpgradePaths[0].Versions[0]
+	if got, want := version.BundleVersion, "1.5.0"; got != want {
+		t.Errorf("BundleVersion = %q, want %q", got, want)
+	}
+	if got, want := version.Channel, "aws-stable"; got != want {
+		t.Errorf("Channel = %q, want %q", got, want)
+	}
+}
+
+func TestApplyVersionOverrides_NoMatchLeavesVersionUnchanged(t *testing.T) {
+	cfg := &Config{
+		UpgradePaths: []UpgradePath{{
+			Versions: []Version{{
+				BundleVersion: "1.0.0",
+				Overrides: map[string]VersionOverride{
+					"cloud=azure": {BundleVersion: "2.0.0"},
+				},
+			}},
+		}},
+	}
+
+	applyVersionOverrides(cfg, map[string]string{"cloud": "gcp"})
+
+	if got, want := cfg.UpgradePaths[0].Versions[0].BundleVersion, "1.0.0"; got != want {
+		t.Errorf("BundleVersion = %q, want %q", got, want)
+	}
+}
+
+func TestApplyVersionOverrides_OnlySetFieldsAreApplied(t *testing.T) {
+	cfg := &Config{
+		UpgradePaths: []UpgradePath{{
+			Versions: []Version{{
+				BundleVersion: "1.0.0",
+				Channel:       "stable",
+				Overrides: map[string]VersionOverride{
+					"cloud=aws": {BundleVersion: "1.5.0"},
+				},
+			}},
+		}},
+	}
+
+	applyVersionOverrides(cfg, map[string]string{"cloud": "aws"})
+
+	version := cfg.UpgradePaths[0].Versions[0]
+	if got, want := version.BundleVersion, "1.5.0"; got != want {
+		t.Errorf("BundleVersion = %q, want %q", got, want)
+	}
+	if got, want := version.Channel, "stable"; got != want {
+		t.Errorf("Channel = %q, want %q (should be untouched by an override that doesn't set it)", got, want)
+	}
+}
+
+// TestRenderConfig_RendersNestedInterfaceMapValues asserts renderConfig expands placeholders
+// nested inside a Version.Values map[string]interface{} (a Helm chart values map), whose entries
+// have reflect.Kind() Interface rather than String/Struct/Slice/Map/Ptr.
+func TestRenderConfig_RendersNestedInterfaceMapValues(t *testing.T) {
+	cfg := &Config{
+		UpgradePaths: []UpgradePath{
+			{
+				Versions: []Version{
+					{
+						Values: map[string]interface{}{
+							"image": map[string]interface{}{
+								"registry": "{{ .Params.registry }}",
+							},
+							"tags": []interface{}{"{{ .Params.tag }}"},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	params := map[string]string{"registry": "registry.example.com", "tag": "v1.2.3"}
+	if err := renderConfig(cfg, params); err != nil {
+		t.Fatalf("renderConfig() error = %v", err)
+	}
+
+	version := cfg.UpgradePaths[0].Versions[0]
+	image, _ := version.Values["image"].(map[string]interface{})
+	if got, want := image["registry"], "registry.example.com"; got != want {
+		t.Errorf("values.image.registry = %q, want %q", got, want)
+	}
+	tags, _ := version.Values["tags"].([]interface{})
+	if len(tags) != 1 || tags[0] != "v1.2.3" {
+		t.Errorf("values.tags = %v, want [v1.2.3]", tags)
+	}
+}
+
+func TestDedupSorted(t *testing.T) {
+	got := dedupSorted([]string{"b", "a", "b", "c", "a"})
+	want := []string{"a", "b", "c"}
+	if !sort.StringsAreSorted(got) || len(got) != len(want) {
+		t.Fatalf("dedupSorted() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("dedupSorted() = %v, want %v", got, want)
+		}
+	}
+}
@@ -4,29 +4,65 @@ package git
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
-	"net/url"
+	stdhttp "net/http"
 	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
 
-	"github.com/AlaudaDevops/tools-upgrade-test/pkg/config"
-	upctx "github.com/AlaudaDevops/tools-upgrade-test/pkg/context"
-	"github.com/AlaudaDevops/tools-upgrade-test/pkg/exec"
+	"github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/client"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"github.com/go-git/go-git/v5/storage/memory"
+
+	"github.com/AlaudaDevops/upgrade-test/pkg/config"
+	upctx "github.com/AlaudaDevops/upgrade-test/pkg/context"
+	"github.com/AlaudaDevops/upgrade-test/pkg/exec"
 	"go.uber.org/zap"
 )
 
+// Auth resolves the transport.AuthMethod used to authenticate a clone, so callers can plug
+// in token providers (bearer/GitHub App/netrc lookup) without GitManager knowing about them
+type Auth interface {
+	// AuthMethod returns the go-git auth method to use for repoURL
+	AuthMethod(repoURL string) (transport.AuthMethod, error)
+}
+
+// basicAuth is the default Auth implementation, built from a static username/password pair
+type basicAuth struct {
+	username string
+	password string
+}
+
+func (a *basicAuth) AuthMethod(repoURL string) (transport.AuthMethod, error) {
+	if a.username == "" && a.password == "" {
+		if strings.HasPrefix(repoURL, "git@") || strings.HasPrefix(repoURL, "ssh://") {
+			return ssh.DefaultAuthBuilder("git")
+		}
+		return nil, nil
+	}
+	return &githttp.BasicAuth{Username: a.username, Password: a.password}, nil
+}
+
 // GitManager handles git operations
 type GitManager struct {
 	// Base directory for git operations
 	baseDir string
 	// Repository URL
 	repoURL string
-	// Username for git authentication
-	username string
-	// Password for git authentication
-	password string
+	// Auth resolves credentials for repoURL, defaults to username/password basic auth
+	auth Auth
+	// TLS options used when talking to the git server
+	tls config.TLSConfig
+	// Path to the CA bundle materialized on disk, empty if tls.CABundle is unset
+	caBundlePath string
 }
 
 // sanitizePath ensures directory names only contain allowed characters (0-9, A-Z, a-z, _, -, .)
@@ -62,8 +98,14 @@ func sanitizePath(path string) string {
 	return strings.Join(parts, string(os.PathSeparator))
 }
 
-// NewGitManager creates a new GitManager instance
-func NewGitManager(baseDir, repoURL, username, password string) (*GitManager, error) {
+// NewGitManager creates a new GitManager instance using basic-auth credentials.
+// Use NewGitManagerWithAuth to plug in a different Auth (token provider, GitHub App, netrc, ...)
+func NewGitManager(baseDir, repoURL, username, password string, tls config.TLSConfig) (*GitManager, error) {
+	return NewGitManagerWithAuth(baseDir, repoURL, &basicAuth{username: username, password: password}, tls)
+}
+
+// NewGitManagerWithAuth creates a new GitManager instance with a caller-provided Auth
+func NewGitManagerWithAuth(baseDir, repoURL string, auth Auth, tls config.TLSConfig) (*GitManager, error) {
 	// Sanitize the base directory path
 	sanitizedBaseDir := sanitizePath(baseDir)
 
@@ -72,12 +114,101 @@ func NewGitManager(baseDir, repoURL, username, password string) (*GitManager, er
 		return nil, fmt.Errorf("failed to create base directory: %v", err)
 	}
 
-	return &GitManager{
-		baseDir:  sanitizedBaseDir,
-		repoURL:  repoURL,
-		username: username,
-		password: password,
-	}, nil
+	g := &GitManager{
+		baseDir: sanitizedBaseDir,
+		repoURL: repoURL,
+		auth:    auth,
+		tls:     tls,
+	}
+
+	caBundlePath, err := g.materializeCABundle()
+	if err != nil {
+		return nil, fmt.Errorf("failed to materialize CA bundle: %v", err)
+	}
+	g.caBundlePath = caBundlePath
+
+	if err := g.installTLSTransport(); err != nil {
+		return nil, fmt.Errorf("failed to install TLS transport: %v", err)
+	}
+
+	return g, nil
+}
+
+// installTLSTransport registers go-git's "https" protocol with an *http.Client built from
+// g.tls, so git.PlainCloneContext itself trusts the configured CA bundle / honours
+// InsecureSkipVerify / presents a client certificate, the same TLS policy tlsEnv() forwards to
+// downstream `make` targets. Without this, go-git falls back to Go's default HTTP client and the
+// system trust store, and a clone against a self-signed host fails TLS verification regardless
+// of what's configured. A no-op if no TLS options were configured.
+func (g *GitManager) installTLSTransport() error {
+	if g.caBundlePath == "" && !g.tls.InsecureSkipVerify && g.tls.ClientCert == "" {
+		return nil
+	}
+
+	tlsConfig := &tls.Config{InsecureSkipVerify: g.tls.InsecureSkipVerify}
+
+	if g.caBundlePath != "" {
+		pemBytes, err := os.ReadFile(g.caBundlePath)
+		if err != nil {
+			return fmt.Errorf("failed to read CA bundle: %v", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pemBytes) {
+			return fmt.Errorf("failed to parse CA bundle %s as PEM", g.caBundlePath)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if g.tls.ClientCert != "" && g.tls.ClientKey != "" {
+		cert, err := tls.LoadX509KeyPair(g.tls.ClientCert, g.tls.ClientKey)
+		if err != nil {
+			return fmt.Errorf("failed to load client certificate: %v", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	httpClient := &stdhttp.Client{Transport: &stdhttp.Transport{TLSClientConfig: tlsConfig}}
+	client.InstallProtocol("https", githttp.NewClient(httpClient))
+	return nil
+}
+
+// materializeCABundle writes tls.CABundle to a file under baseDir so it can be referenced
+// by git's TLS client. If CABundle is already a path to an existing file, that path is reused
+// as-is. Returns an empty path if no CA bundle was configured.
+func (g *GitManager) materializeCABundle() (string, error) {
+	if g.tls.CABundle == "" {
+		return "", nil
+	}
+
+	if _, err := os.Stat(g.tls.CABundle); err == nil {
+		return g.tls.CABundle, nil
+	}
+
+	caBundlePath := filepath.Join(g.baseDir, "ca-bundle.pem")
+	if err := os.WriteFile(caBundlePath, []byte(g.tls.CABundle), 0600); err != nil {
+		return "", fmt.Errorf("failed to write CA bundle: %v", err)
+	}
+
+	return caBundlePath, nil
+}
+
+// tlsEnv returns the environment variables used to make downstream build tooling
+// (e.g. `make` targets shelling out to git/curl) trust the configured CA bundle
+func (g *GitManager) tlsEnv() []string {
+	var env []string
+	if g.caBundlePath != "" {
+		env = append(env, "GIT_SSL_CAINFO="+g.caBundlePath, "CURL_CA_BUNDLE="+g.caBundlePath)
+	}
+	if g.tls.InsecureSkipVerify {
+		env = append(env, "GIT_SSL_NO_VERIFY=true")
+	}
+	if g.tls.ClientCert != "" {
+		env = append(env, "GIT_SSL_CERT="+g.tls.ClientCert)
+	}
+	if g.tls.ClientKey != "" {
+		env = append(env, "GIT_SSL_KEY="+g.tls.ClientKey)
+	}
+	return env
 }
 
 // CloneResult is the result of a clone operation
@@ -90,148 +221,141 @@ type CloneResult struct {
 	OperatorImage string
 }
 
-// CloneAndBuild clones the repository and builds the operator
+// Clone clones the repository at gitConfig.Revision (a branch, tag or full SHA) into a
+// fresh directory under baseDir. A branch or tag found on the remote is fetched shallow
+// (depth 1) to keep large monorepo clones fast; a full SHA, or a revision that matches no
+// remote branch or tag, gets a full clone
 func (g *GitManager) Clone(ctx context.Context, version string, gitConfig *config.GitConfig) (string, error) {
+	logger := upctx.LoggerFromContext(ctx)
+
 	// Create a unique directory for this clone
 	cloneDir := filepath.Join(g.baseDir, version)
 	// If the cloneDir already exists, remove it and recreate to ensure a clean environment
 	if _, err := os.Stat(cloneDir); err == nil {
-		// Remove the existing directory and its contents
 		if removeErr := os.RemoveAll(cloneDir); removeErr != nil {
 			return "", fmt.Errorf("failed to remove existing clone directory: %v", removeErr)
 		}
 	}
 
-	if err := os.MkdirAll(cloneDir, 0755); err != nil {
-		return "", fmt.Errorf("failed to create clone directory: %v", err)
+	auth, err := g.auth.AuthMethod(g.repoURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve git auth: %v", err)
 	}
 
-	// Clone the repository
-	if err := g.cloneRepository(ctx, cloneDir, gitConfig.Revision); err != nil {
-		return "", fmt.Errorf("failed to clone repository: %v", err)
+	revision := gitConfig.Revision
+	logger.Info("starting git repository clone",
+		zap.String("targetDir", cloneDir),
+		zap.String("revision", revision),
+		zap.String("repository", exec.Redact(g.repoURL)))
+
+	// A full SHA can only be checked out after a clone (shallow clones only fetch refs), and a
+	// shallow fetch of a branch or tag needs the right refs/heads/<rev> vs refs/tags/<rev> up
+	// front, so ask the remote which kind revision is before picking the shallow reference
+	var refName plumbing.ReferenceName
+	if !isFullSHA(revision) {
+		refName, err = g.resolveReferenceName(ctx, auth, revision)
+		if err != nil {
+			logger.Warn("failed to resolve revision against remote refs, falling back to a full clone",
+				zap.String("revision", revision), zap.Error(err))
+		}
 	}
 
-	return cloneDir, nil
-}
+	var repo *git.Repository
+	if refName != "" {
+		repo, err = git.PlainCloneContext(ctx, cloneDir, false, &git.CloneOptions{
+			URL:           g.repoURL,
+			Auth:          auth,
+			Depth:         1,
+			Tags:          git.NoTags,
+			ReferenceName: refName,
+			SingleBranch:  true,
+		})
+	}
+	if refName == "" || err != nil {
+		// revision is a full SHA, or didn't match a branch/tag on the remote: go-git can't
+		// shallow-fetch an arbitrary commit by hash from most servers, so fall back to a full
+		// clone and check it out directly
+		repo, err = git.PlainCloneContext(ctx, cloneDir, false, &git.CloneOptions{
+			URL:  g.repoURL,
+			Auth: auth,
+			Tags: git.NoTags,
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to clone repository: %v", err)
+		}
+	}
 
-// Build builds the operator
-func (g *GitManager) Build(ctx context.Context, cloneDir string, buildCommand string) error {
-	if err := g.buildOperator(ctx, cloneDir, buildCommand); err != nil {
-		return fmt.Errorf("failed to build operator: %v", err)
+	if err := g.checkout(repo, revision); err != nil {
+		return "", fmt.Errorf("failed to checkout revision %s: %v", revision, err)
 	}
 
-	return nil
+	logger.Info("successfully cloned repository",
+		zap.String("targetDir", cloneDir),
+		zap.String("revision", revision))
+	return cloneDir, nil
 }
 
-// cloneRepository clones the repository to the specified directory
-
-func (g *GitManager) cloneRepository(ctx context.Context, targetDir, revision string) error {
-	logger := upctx.LoggerFromContext(ctx)
-	logger.Info("starting git repository clone",
-		zap.String("targetDir", targetDir),
-		zap.String("revision", revision),
-		zap.String("repository", g.repoURL))
-
-	// Create a temporary directory for cloning to avoid conflicts and ensure isolation
-	tempDir, err := os.MkdirTemp("", "git-clone-*")
+// checkout resolves revision (branch, tag or full SHA) against repo and checks out the worktree
+func (g *GitManager) checkout(repo *git.Repository, revision string) error {
+	wt, err := repo.Worktree()
 	if err != nil {
-		logger.Error("failed to create temporary directory",
-			zap.Error(err))
-		return fmt.Errorf("failed to create temporary directory for git clone: %v", err)
-	}
-	// Clean up the temporary directory after use
-	defer func() {
-		if err := os.RemoveAll(tempDir); err != nil {
-			logger.Warn("failed to cleanup temporary directory",
-				zap.String("tempDir", tempDir),
-				zap.Error(err))
-		}
-	}()
-
-	logger.Debug("initializing git repository",
-		zap.String("tempDir", tempDir),
-		zap.String("revision", revision))
-	initResult := exec.RunCommand(ctx, exec.Command{Name: "git", Args: []string{"init"}, Dir: tempDir})
-	if initResult.Err != nil {
-		logger.Error("failed to initialize git repository",
-			zap.String("tempDir", tempDir),
-			zap.Error(initResult.Err))
-		return fmt.Errorf("failed to initialize git repository: %v", initResult.Err)
+		return err
 	}
 
-	// Configure git credentials if provided
-	if g.username != "" && g.password != "" {
-		logger.Debug("configuring git credentials")
-		// Set credential helper to store credentials
-		credentialResult := exec.RunCommand(ctx, exec.Command{
-			Name: "git",
-			Args: []string{"config", "credential.helper", "store"},
-			Dir:  tempDir,
-		})
-		if credentialResult.Err != nil {
-			logger.Error("failed to configure git credentials",
-				zap.Error(credentialResult.Err))
-			return fmt.Errorf("failed to configure git credentials: %v", credentialResult.Err)
-		}
-
-		gitUrl, err := url.Parse(g.repoURL)
+	var hash *plumbing.Hash
+	if isFullSHA(revision) {
+		h := plumbing.NewHash(revision)
+		hash = &h
+	} else {
+		resolved, err := repo.ResolveRevision(plumbing.Revision(revision))
 		if err != nil {
-			logger.Error("failed to parse git URL",
-				zap.String("repoURL", g.repoURL),
-				zap.Error(err))
-			return fmt.Errorf("failed to parse git URL: %v", err)
+			return fmt.Errorf("failed to resolve revision: %v", err)
 		}
-		gitUrl.User = url.UserPassword(g.username, g.password)
-		g.repoURL = gitUrl.String()
+		hash = resolved
 	}
 
-	// Add remote
-	logger.Debug("adding remote",
-		zap.String("repoURL", g.repoURL))
-	remoteResult := exec.RunCommand(ctx, exec.Command{Name: "git", Args: []string{"remote", "add", "origin", g.repoURL}, Dir: tempDir})
-	if remoteResult.Err != nil {
-		logger.Error("failed to add remote",
-			zap.String("repoURL", g.repoURL),
-			zap.Error(remoteResult.Err))
-		return fmt.Errorf("failed to add remote: %v", remoteResult.Err)
-	}
+	return wt.Checkout(&git.CheckoutOptions{Hash: *hash})
+}
 
-	// Fetch the specified revision
-	logger.Debug("fetching revision",
-		zap.String("revision", revision))
-	fetchResult := exec.RunCommand(ctx, exec.Command{Name: "git", Args: []string{"fetch", "origin", revision}, Dir: tempDir})
-	if fetchResult.Err != nil {
-		logger.Error("failed to fetch revision",
-			zap.String("revision", revision),
-			zap.Error(fetchResult.Err))
-		return fmt.Errorf("failed to fetch revision: %v", fetchResult.Err)
+// resolveReferenceName lists repoURL's remote refs and reports whether revision names a branch
+// or a tag, so Clone can shallow-fetch refs/heads/<revision> or refs/tags/<revision> instead of
+// assuming it's always a branch. Returns "" (and no error) if revision matches neither, in which
+// case Clone falls back to a full clone.
+func (g *GitManager) resolveReferenceName(ctx context.Context, auth transport.AuthMethod, revision string) (plumbing.ReferenceName, error) {
+	remote := git.NewRemote(memory.NewStorage(), &gitconfig.RemoteConfig{Name: "origin", URLs: []string{g.repoURL}})
+	refs, err := remote.ListContext(ctx, &git.ListOptions{Auth: auth})
+	if err != nil {
+		return "", fmt.Errorf("failed to list remote refs: %v", err)
 	}
 
-	// Checkout the revision
-	logger.Debug("checking out revision",
-		zap.String("revision", revision))
-	checkoutResult := exec.RunCommand(ctx, exec.Command{Name: "git", Args: []string{"checkout", "FETCH_HEAD"}, Dir: tempDir})
-	if checkoutResult.Err != nil {
-		logger.Error("failed to checkout revision",
-			zap.String("revision", revision),
-			zap.Error(checkoutResult.Err))
-		return fmt.Errorf("failed to checkout revision: %v", checkoutResult.Err)
+	branchRef := plumbing.NewBranchReferenceName(revision)
+	tagRef := plumbing.NewTagReferenceName(revision)
+	for _, ref := range refs {
+		switch ref.Name() {
+		case branchRef:
+			return branchRef, nil
+		case tagRef:
+			return tagRef, nil
+		}
 	}
 
-	// Copy the repository to the target directory
-	logger.Debug("copying repository to target directory",
-		zap.String("targetDir", targetDir))
-	copyResult := exec.RunCommand(ctx, exec.Command{Name: "cp", Args: []string{"-rf", tempDir + "/", targetDir}, Dir: g.baseDir})
-	if copyResult.Err != nil {
-		logger.Error("failed to copy repository to target directory",
-			zap.String("targetDir", targetDir),
-			zap.Error(copyResult.Err))
-		return fmt.Errorf("failed to copy repository to target directory: %v", copyResult.Err)
+	return "", nil
+}
+
+var fullSHAPattern = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+// isFullSHA reports whether revision looks like a full (40 hex char) commit SHA, as opposed to
+// a branch or tag name
+func isFullSHA(revision string) bool {
+	return fullSHAPattern.MatchString(revision)
+}
+
+// Build builds the operator
+func (g *GitManager) Build(ctx context.Context, cloneDir string, buildCommand string) error {
+	if err := g.buildOperator(ctx, cloneDir, buildCommand); err != nil {
+		return fmt.Errorf("failed to build operator: %v", err)
 	}
 
-	logger.Info("successfully cloned repository",
-		zap.String("targetDir", targetDir),
-		zap.String("revision", revision))
 	return nil
 }
 
@@ -242,8 +366,9 @@ func (g *GitManager) buildOperator(ctx context.Context, repoPath string, buildCo
 		zap.String("repoPath", repoPath),
 		zap.String("buildCommand", buildCommand))
 
-	// Execute the build command
-	buildResult := exec.RunCommand(ctx, exec.Command{Name: "sh", Args: []string{"-c", buildCommand}, Dir: repoPath})
+	// Execute the build command, forwarding the TLS trust settings so `make` targets talking
+	// to the same enterprise git host also trust the CA bundle
+	buildResult := exec.RunCommand(ctx, exec.Command{Name: "sh", Args: []string{"-c", buildCommand}, Dir: repoPath, Env: g.tlsEnv()})
 	if buildResult.Err != nil {
 		logger.Error("failed to execute build command",
 			zap.String("buildCommand", buildCommand),
@@ -0,0 +1,146 @@
+// Package errors defines the terminal install error types returned by pkg/operator/operatorhub,
+// so callers such as the upgrade command can distinguish terminal install failures from
+// ordinary timeouts.
+package errors
+
+import (
+	stderrors "errors"
+	"fmt"
+)
+
+// ErrInstallPlanFailed is returned when an InstallPlan reaches a terminal failure condition
+// (e.g. bundle unpack failure) instead of becoming ready, so the caller can stop polling
+// immediately rather than waiting out the full timeout.
+type ErrInstallPlanFailed struct {
+	// InstallPlanName is the name of the InstallPlan that failed
+	InstallPlanName string
+	// Reason is the condition reason reported by OLM, e.g. InstallComponentFailed, BundleLookupFailed
+	Reason string
+	// Message is the human readable condition message
+	Message string
+}
+
+func (e *ErrInstallPlanFailed) Error() string {
+	return fmt.Sprintf("install plan %s failed terminally: %s: %s", e.InstallPlanName, e.Reason, e.Message)
+}
+
+// ErrCSVFailed is returned when a ClusterServiceVersion reaches a terminal Failed phase/reason,
+// or never reports a status.phase at all past a grace period (suggesting it's malformed), instead
+// of becoming Succeeded.
+type ErrCSVFailed struct {
+	// CSVName is the name of the ClusterServiceVersion that failed
+	CSVName string
+	// Reason is the condition/status reason reported by OLM, e.g. InstallCheckFailed, or
+	// "NoStatusPhase" when status.phase never appeared
+	Reason string
+	// Message is the human readable status message
+	Message string
+}
+
+func (e *ErrCSVFailed) Error() string {
+	return fmt.Sprintf("csv %s failed terminally: %s: %s", e.CSVName, e.Reason, e.Message)
+}
+
+// terminalCSVReasons are ClusterServiceVersion status.reason values that will never resolve on
+// their own, mirroring OLM's own terminal CSV phases
+var terminalCSVReasons = map[string]bool{
+	"InstallCheckFailed":       true,
+	"InvalidInstallModes":      true,
+	"ComponentFailedNoRetry":   true,
+	"UnsupportedOperatorGroup": true,
+}
+
+// IsTerminalCSVReason reports whether reason indicates a permanent CSV failure
+func IsTerminalCSVReason(reason string) bool {
+	return terminalCSVReasons[reason]
+}
+
+// CSVVersion names a single CSV and the version OLM reports for it, used to report which
+// entries in an InstallPlan violated an version gating policy
+type CSVVersion struct {
+	Name    string
+	Version string
+}
+
+// ErrInstallPlanVersionNotAllowed is returned when an InstallPlan's clusterServiceVersionNames
+// contains a CSV whose version doesn't match the caller's allow-list, so Manual approval is
+// withheld instead of blindly approving whatever OLM resolved.
+type ErrInstallPlanVersionNotAllowed struct {
+	// InstallPlanName is the name of the InstallPlan that was left un-approved
+	InstallPlanName string
+	// Allowed is the version policy the plan's CSVs were checked against
+	Allowed []string
+	// Related lists every CSV in the plan and the version it reported, for diagnosing which
+	// ones fell outside Allowed
+	Related []CSVVersion
+}
+
+func (e *ErrInstallPlanVersionNotAllowed) Error() string {
+	return fmt.Sprintf("install plan %s not approved: csv versions %v are not all within allowed versions %v", e.InstallPlanName, e.Related, e.Allowed)
+}
+
+// ErrSubscriptionUnresolvable is returned when a Subscription reports a ResolutionFailed
+// condition with reason ConstraintsNotSatisfiable and stays that way past the configured
+// resolution grace period: no InstallPlan will ever be created while this holds, so the caller
+// should stop waiting instead of silently polling until its own timeout expires.
+type ErrSubscriptionUnresolvable struct {
+	// SubscriptionName is the name of the Subscription that failed to resolve
+	SubscriptionName string
+	// Reason is the condition reason reported by OLM, e.g. ConstraintsNotSatisfiable
+	Reason string
+	// Message is the human readable constraint text reported by the resolver
+	Message string
+}
+
+func (e *ErrSubscriptionUnresolvable) Error() string {
+	return fmt.Sprintf("subscription %s could not be resolved: %s: %s", e.SubscriptionName, e.Reason, e.Message)
+}
+
+// terminalReasons are InstallPlan condition reasons that will never resolve on their own
+var terminalReasons = map[string]bool{
+	"InstallComponentFailed": true,
+	"BundleLookupFailed":     true,
+	"BundleUnpackFailed":     true,
+}
+
+// IsTerminalReason reports whether reason indicates a permanent InstallPlan failure
+func IsTerminalReason(reason string) bool {
+	return terminalReasons[reason]
+}
+
+// FatalError wraps an error to mark it as a terminal failure that must not be retried, mirroring
+// OLM's NewFatalError/IsFatal pattern
+type FatalError struct {
+	err error
+}
+
+// NewFatalError wraps err as a terminal failure
+func NewFatalError(err error) *FatalError {
+	return &FatalError{err: err}
+}
+
+func (e *FatalError) Error() string { return e.err.Error() }
+func (e *FatalError) Unwrap() error { return e.err }
+
+// IsFatal reports whether err represents a terminal failure that callers should surface
+// immediately instead of retrying, mirroring OLM's NewFatalError/IsFatal pattern: either an
+// explicit *FatalError, or one of this package's own terminal error types (ErrInstallPlanFailed,
+// ErrCSVFailed, ErrSubscriptionUnresolvable), which are only ever constructed for conditions OLM
+// will never resolve on its own. It is the single predicate callers should check for "is this
+// install unrecoverable", regardless of which terminal condition produced the error.
+func IsFatal(err error) bool {
+	var fatal *FatalError
+	if stderrors.As(err, &fatal) {
+		return true
+	}
+	var installPlanErr *ErrInstallPlanFailed
+	if stderrors.As(err, &installPlanErr) {
+		return true
+	}
+	var csvErr *ErrCSVFailed
+	if stderrors.As(err, &csvErr) {
+		return true
+	}
+	var subErr *ErrSubscriptionUnresolvable
+	return stderrors.As(err, &subErr)
+}
@@ -3,27 +3,28 @@ package operator
 import (
 	"k8s.io/client-go/rest"
 
-	"github.com/AlaudaDevops/tools-upgrade-test/pkg/config"
-	"github.com/AlaudaDevops/tools-upgrade-test/pkg/operator/local"
-	"github.com/AlaudaDevops/tools-upgrade-test/pkg/operator/operatorhub"
+	"github.com/AlaudaDevops/upgrade-test/pkg/config"
+	"github.com/AlaudaDevops/upgrade-test/pkg/operator/helm"
+	"github.com/AlaudaDevops/upgrade-test/pkg/operator/local"
+	"github.com/AlaudaDevops/upgrade-test/pkg/operator/operatorhub"
 )
 
 // OperatorType represents the type of operator implementation
 type OperatorType string
 
 const (
-	// OperatorTypeReal represents the real operator implementation
+	// OperatorTypeOperatorHub represents the OLM/OperatorHub implementation
 	OperatorTypeOperatorHub OperatorType = "operatorhub"
 	OperatorTypeLocal       OperatorType = "local"
+	// OperatorTypeHelm represents operators shipped as a Helm chart
+	OperatorTypeHelm OperatorType = "helm"
 )
 
 type OperatorOptions struct {
-	// OperatorHub options
-	Config    *rest.Config
-	Namespace string
-	Name      string
+	// Config is the rest config used to talk to the cluster, used by operatorhub and helm
+	Config *rest.Config
 
-	// local deploy options
+	// OperatorConfig carries the full operator configuration, used by every operator type
 	OperatorConfig config.OperatorConfig
 }
 
@@ -40,8 +41,10 @@ func NewOperatorFactory() *OperatorFactory {
 func (f *OperatorFactory) CreateOperator(operatorType OperatorType, options OperatorOptions) (OperatorInterface, error) {
 	switch operatorType {
 	case OperatorTypeLocal:
-		return local.NewLocalOperator(options.OperatorConfig.Workspace, options.OperatorConfig.Command)
+		return local.NewLocalOperator(options.OperatorConfig)
+	case OperatorTypeHelm:
+		return helm.NewOperator(options.Config, options.OperatorConfig)
 	default:
-		return operatorhub.NewOperator(options.Config, options.Namespace, options.Name)
+		return operatorhub.NewOperator(options.Config, options.OperatorConfig)
 	}
 }
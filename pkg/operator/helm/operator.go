@@ -0,0 +1,263 @@
+// Package helm implements operator.OperatorInterface for operators shipped as Helm charts. Each
+// UpgradeOperator call runs "helm upgrade --install" semantics via the Helm SDK, waits for the
+// release's Deployments/StatefulSets to become ready, and rolls back to the previous revision on
+// failure so the next hop in an upgrade path sees a known-good baseline.
+package helm
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"helm.sh/helm/v3/pkg/action"
+	helmchart "helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/cli"
+	"helm.sh/helm/v3/pkg/release"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+	"knative.dev/pkg/logging"
+
+	"github.com/AlaudaDevops/upgrade-test/pkg/config"
+	"github.com/AlaudaDevops/upgrade-test/pkg/operator/watch"
+)
+
+var (
+	deploymentGVR = schema.GroupVersionResource{
+		Group:    "apps",
+		Version:  "v1",
+		Resource: "deployments",
+	}
+	statefulSetGVR = schema.GroupVersionResource{
+		Group:    "apps",
+		Version:  "v1",
+		Resource: "statefulsets",
+	}
+)
+
+// Operator drives helm upgrade/rollback for a single release
+type Operator struct {
+	actionConfig *action.Configuration
+	client       dynamic.Interface
+	watcher      *watch.Watcher
+
+	namespace   string
+	releaseName string
+
+	timeout  time.Duration
+	interval time.Duration
+}
+
+// NewOperator creates an Operator that manages the Helm release options.Name in
+// options.Namespace on the cluster described by restConfig
+func NewOperator(restConfig *rest.Config, options config.OperatorConfig) (*Operator, error) {
+	client, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	actionConfig := new(action.Configuration)
+	getter := &restConfigGetter{restConfig: restConfig, namespace: options.Namespace}
+	if err := actionConfig.Init(getter, options.Namespace, "secret", func(format string, v ...interface{}) {}); err != nil {
+		return nil, fmt.Errorf("failed to initialize helm action config: %v", err)
+	}
+
+	return &Operator{
+		actionConfig: actionConfig,
+		client:       client,
+		watcher:      watch.NewWatcher(client, options.Interval),
+		namespace:    options.Namespace,
+		releaseName:  options.Name,
+		timeout:      options.Timeout,
+		interval:     options.Interval,
+	}, nil
+}
+
+// UpgradeOperator installs or upgrades the release to version's chart, waits for it to report
+// deployed and its Deployments/StatefulSets to be ready, and rolls back to the previous revision
+// if either step fails
+func (o *Operator) UpgradeOperator(ctx context.Context, version config.Version) error {
+	log := logging.FromContext(ctx)
+
+	chrt, err := o.loadChart(version)
+	if err != nil {
+		return fmt.Errorf("failed to load chart: %v", err)
+	}
+
+	values, err := o.mergeValues(version)
+	if err != nil {
+		return fmt.Errorf("failed to merge values: %v", err)
+	}
+
+	previousRevision, hasPrevious := o.lastDeployedRevision()
+
+	log.Infow("upgrading helm release", "release", o.releaseName, "namespace", o.namespace, "chart", version.ChartName, "version", version.ChartVersion)
+
+	rel, err := o.upgradeOrInstall(chrt, values)
+	if err != nil {
+		if rbErr := o.rollback(ctx, hasPrevious, previousRevision); rbErr != nil {
+			return fmt.Errorf("failed to upgrade release %s: %v (rollback also failed: %v)", o.releaseName, err, rbErr)
+		}
+		return fmt.Errorf("failed to upgrade release %s: %v", o.releaseName, err)
+	}
+
+	if err := o.waitDeployed(ctx, rel); err != nil {
+		if rbErr := o.rollback(ctx, hasPrevious, previousRevision); rbErr != nil {
+			return fmt.Errorf("release %s did not become healthy: %v (rollback also failed: %v)", o.releaseName, err, rbErr)
+		}
+		return fmt.Errorf("release %s did not become healthy, rolled back: %v", o.releaseName, err)
+	}
+
+	log.Infow("helm release upgraded successfully", "release", o.releaseName, "revision", rel.Version)
+	return nil
+}
+
+// loadChart resolves version's chart from ChartRepo/ChartName/ChartVersion and loads it
+func (o *Operator) loadChart(version config.Version) (*helmchart.Chart, error) {
+	if version.ChartName == "" {
+		return nil, fmt.Errorf("chartName is not set")
+	}
+
+	client := action.NewInstall(o.actionConfig)
+	client.ChartPathOptions.RepoURL = version.ChartRepo
+	client.ChartPathOptions.Version = version.ChartVersion
+
+	settings := cli.New()
+	path, err := client.ChartPathOptions.LocateChart(version.ChartName, settings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate chart %s: %v", version.ChartName, err)
+	}
+
+	chrt, err := loader.Load(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load chart from %s: %v", path, err)
+	}
+
+	return chrt, nil
+}
+
+// mergeValues layers version.Values over the content of version.ValuesFiles, in order
+func (o *Operator) mergeValues(version config.Version) (map[string]interface{}, error) {
+	values := map[string]interface{}{}
+
+	for _, path := range version.ValuesFiles {
+		fileValues, err := chartutil.ReadValuesFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read values file %s: %v", path, err)
+		}
+		values = chartutil.CoalesceTables(fileValues, values)
+	}
+
+	if version.Values != nil {
+		values = chartutil.CoalesceTables(version.Values, values)
+	}
+
+	return values, nil
+}
+
+// lastDeployedRevision returns the currently deployed revision number for the release, if any
+func (o *Operator) lastDeployedRevision() (int, bool) {
+	hist := action.NewHistory(o.actionConfig)
+	hist.Max = 1
+	releases, err := hist.Run(o.releaseName)
+	if err != nil || len(releases) == 0 {
+		return 0, false
+	}
+	return releases[len(releases)-1].Version, true
+}
+
+// upgradeOrInstall runs "helm upgrade --install" semantics: upgrade the release if it already
+// exists, otherwise install it
+func (o *Operator) upgradeOrInstall(chrt *helmchart.Chart, values map[string]interface{}) (*release.Release, error) {
+	if _, hasPrevious := o.lastDeployedRevision(); !hasPrevious {
+		client := action.NewInstall(o.actionConfig)
+		client.ReleaseName = o.releaseName
+		client.Namespace = o.namespace
+		client.Timeout = o.timeout
+		client.Wait = true
+		return client.Run(chrt, values)
+	}
+
+	client := action.NewUpgrade(o.actionConfig)
+	client.Namespace = o.namespace
+	client.Timeout = o.timeout
+	client.Wait = true
+	return client.Run(o.releaseName, chrt, values)
+}
+
+// rollback rolls the release back to previousRevision if one exists. Releases with no previous
+// revision (the first install failed) are uninstalled instead, since there's nothing to roll
+// back to.
+func (o *Operator) rollback(ctx context.Context, hasPrevious bool, previousRevision int) error {
+	log := logging.FromContext(ctx)
+
+	if !hasPrevious {
+		log.Infow("no previous revision to roll back to, uninstalling failed release", "release", o.releaseName)
+		uninstall := action.NewUninstall(o.actionConfig)
+		_, err := uninstall.Run(o.releaseName)
+		return err
+	}
+
+	log.Infow("rolling back helm release", "release", o.releaseName, "revision", previousRevision)
+	rollback := action.NewRollback(o.actionConfig)
+	rollback.Version = previousRevision
+	rollback.Wait = true
+	rollback.Timeout = o.timeout
+	return rollback.Run(o.releaseName)
+}
+
+// waitDeployed confirms rel's status is deployed and walks its Deployments/StatefulSets,
+// bounded by o.timeout
+func (o *Operator) waitDeployed(ctx context.Context, rel *release.Release) error {
+	if rel.Info == nil || rel.Info.Status != release.StatusDeployed {
+		return fmt.Errorf("release %s is in status %v, expected deployed", o.releaseName, rel.Info)
+	}
+
+	return o.VerifyOperatorHealth(ctx)
+}
+
+// VerifyOperatorHealth waits for every Deployment and StatefulSet labeled
+// app.kubernetes.io/instance=<release> to report enough ready replicas, bounded by o.timeout
+func (o *Operator) VerifyOperatorHealth(ctx context.Context) error {
+	log := logging.FromContext(ctx)
+	log.Infow("verifying helm release health", "release", o.releaseName, "namespace", o.namespace)
+
+	waitCtx, cancel := context.WithTimeout(ctx, o.timeout)
+	defer cancel()
+
+	selector := fmt.Sprintf("app.kubernetes.io/instance=%s", o.releaseName)
+
+	for _, gvr := range []schema.GroupVersionResource{deploymentGVR, statefulSetGVR} {
+		list, err := o.client.Resource(gvr).Namespace(o.namespace).List(waitCtx, metav1.ListOptions{LabelSelector: selector})
+		if err != nil {
+			return fmt.Errorf("failed to list %s for release %s: %v", gvr.Resource, o.releaseName, err)
+		}
+
+		for _, obj := range list.Items {
+			name := obj.GetName()
+			err := o.watcher.WaitForCondition(waitCtx, gvr, o.namespace, name, func(obj *unstructured.Unstructured) (bool, error) {
+				if obj == nil {
+					return false, nil
+				}
+
+				desired, _, _ := unstructured.NestedInt64(obj.Object, "spec", "replicas")
+				if desired == 0 {
+					desired = 1
+				}
+
+				ready, _, _ := unstructured.NestedInt64(obj.Object, "status", "readyReplicas")
+				return ready >= desired, nil
+			})
+			if err != nil {
+				return fmt.Errorf("timed out waiting for %s %s to be ready: %v", gvr.Resource, name, err)
+			}
+		}
+	}
+
+	log.Infow("helm release is healthy", "release", o.releaseName, "namespace", o.namespace)
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/AlaudaDevops/upgrade-test/pkg/config"
+	"github.com/AlaudaDevops/upgrade-test/pkg/report"
 )
 
 // OperatorInterface defines the interface for operator operations
@@ -11,3 +12,40 @@ type OperatorInterface interface {
 	// UpgradeOperator upgrades the operator to the given version
 	UpgradeOperator(ctx context.Context, version config.Version) error
 }
+
+// EphemeralNamespaceOperator is implemented by operators that have a notion of namespace and can
+// run a single hop of an upgrade path against a namespace other than the one they were
+// constructed with. Operators without a namespace concept (e.g. the local operator) don't
+// implement it; callers should type-assert before using it.
+type EphemeralNamespaceOperator interface {
+	OperatorInterface
+
+	// UseNamespace switches the operator to operate against namespace for subsequent calls
+	UseNamespace(ctx context.Context, namespace string) error
+	// TeardownNamespace deletes namespace and waits for it to be gone
+	TeardownNamespace(ctx context.Context, namespace string) error
+}
+
+// ResourceCleaner is implemented by operators that label the cluster resources they manage and
+// can remove them in bulk afterward (e.g. the OperatorHub operator, which labels every
+// Subscription/InstallPlan it creates or approves). Operators without that notion don't implement
+// it; callers should type-assert before using it.
+type ResourceCleaner interface {
+	OperatorInterface
+
+	// CleanupManagedResources removes every resource this operator has created or approved
+	CleanupManagedResources(ctx context.Context) error
+}
+
+// PreflightOperator is implemented by operators that can evaluate a hop's
+// config.Version.Preconditions against currently installed cluster state before UpgradeOperator
+// runs (e.g. the OperatorHub operator, which can inspect the installed CSV and Subscription).
+// Operators without that notion (e.g. the local and helm operators) don't implement it; callers
+// should type-assert before using it.
+type PreflightOperator interface {
+	OperatorInterface
+
+	// Preflight evaluates next.Preconditions against the currently installed CSV and
+	// Subscription, given prev as the version this hop is upgrading from
+	Preflight(ctx context.Context, prev, next config.Version) (report.PreflightReport, error)
+}
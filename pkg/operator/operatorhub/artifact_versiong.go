@@ -89,6 +89,9 @@ func (o *Operator) createArtifactVersion(ctx context.Context, version string, ar
 	return o.client.Resource(artifactVersionGVR).Namespace(systemNamespace).Create(ctx, av, metav1.CreateOptions{})
 }
 
+// waitArtifactVersionPresent polls name's ArtifactVersion until status.phase reaches Present,
+// short-circuiting with status.message if it instead reaches Failed (e.g. a bundle unpack job
+// failure) rather than waiting out the full timeout
 func (o *Operator) waitArtifactVersionPresent(ctx context.Context, name string) (*unstructured.Unstructured, error) {
 	lastResource := &unstructured.Unstructured{}
 	err := wait.PollUntilContextTimeout(ctx, o.interval, o.timeout, true, func(ctx context.Context) (done bool, err error) {
@@ -98,9 +101,14 @@ func (o *Operator) waitArtifactVersionPresent(ctx context.Context, name string)
 		}
 
 		status, _, _ := unstructured.NestedMap(obj.Object, "status")
-		if phase, ok := status["phase"].(string); ok && phase == "Present" {
+		phase, _ := status["phase"].(string)
+		switch phase {
+		case "Present":
 			lastResource = obj
 			return true, nil
+		case "Failed":
+			message, _ := status["message"].(string)
+			return false, fmt.Errorf("artifact version %s failed: %s", name, message)
 		}
 
 		return false, nil
@@ -0,0 +1,43 @@
+package operatorhub
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"knative.dev/pkg/logging"
+)
+
+// VerifyOperatorHealth rechecks the CSV phase one more time after InstallSubscription's own wait
+// (waitCSVsReady, then waitDeploymentsReady for Deployment health) has already completed, to catch
+// any regression that crept in between the two calls. Deployment health is only checked once, by
+// waitDeploymentsReady, to avoid two independent CSV-deployment-list + per-deployment-watch round
+// trips for the same readiness condition.
+func (o *Operator) VerifyOperatorHealth(ctx context.Context, csv string) error {
+	log := logging.FromContext(ctx)
+	log.Infow("verifying operator health", "csv", csv, "namespace", o.namespace)
+
+	waitCtx, cancel := context.WithTimeout(ctx, o.healthTimeout)
+	defer cancel()
+
+	err := o.watcher.WaitForCondition(waitCtx, csvGVR, o.namespace, csv, func(obj *unstructured.Unstructured) (bool, error) {
+		if obj == nil {
+			return false, nil
+		}
+		phase, _, _ := unstructured.NestedString(obj.Object, "status", "phase")
+		switch phase {
+		case "Succeeded":
+			return true, nil
+		case "Failed", "InstallCheckFailed":
+			return false, fmt.Errorf("csv %s is in phase %s", csv, phase)
+		default:
+			return false, nil
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("csv %s is not healthy: %v", csv, err)
+	}
+
+	log.Infow("operator is healthy", "csv", csv, "namespace", o.namespace)
+	return nil
+}
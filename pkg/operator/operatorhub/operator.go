@@ -6,6 +6,8 @@ import (
 	"time"
 
 	"github.com/AlaudaDevops/upgrade-test/pkg/config"
+	"github.com/AlaudaDevops/upgrade-test/pkg/operator/watch"
+	"github.com/AlaudaDevops/upgrade-test/pkg/report"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -20,8 +22,66 @@ type Operator struct {
 	name      string
 	artifact  string
 
+	// operatorGroupMode is the OperatorGroup install mode to ensure before the Subscription
+	// is created: AllNamespaces, OwnNamespace or SingleNamespace
+	operatorGroupMode string
+
+	// watcher backs WaitForCondition-style waits with shared informers instead of independent
+	// polling loops per call
+	watcher *watch.Watcher
+
+	// lastTiming is the HopTiming recorded by the most recent InstallSubscription call
+	lastTiming report.HopTiming
+
+	// lastCSV and lastChannel are the CSV name and channel resolved by the most recent
+	// UpgradeOperator call, for annotating per-version test reports
+	lastCSV     string
+	lastChannel string
+
 	timeout  time.Duration
 	interval time.Duration
+
+	// resolutionGracePeriod bounds how long InstallSubscription waits for OLM to resolve a
+	// Subscription stuck reporting ResolutionFailed/ConstraintsNotSatisfiable before intervening
+	resolutionGracePeriod time.Duration
+
+	// runID and configPath are stamped as annotations on every Subscription/InstallPlan this
+	// Operator manages, alongside the upgrade-test.alaudadevops.io/managed label, so they can be
+	// identified and garbage collected by CleanupManagedResources or `kubectl ... -l`
+	runID      string
+	configPath string
+
+	// healthTimeout bounds VerifyOperatorHealth's wait for the CSV and its Deployments to
+	// report healthy after an upgrade, default is the same as timeout
+	healthTimeout time.Duration
+
+	// approval is the default Subscription installPlanApproval for versions that don't set
+	// their own, "Automatic" or "Manual"
+	approval string
+
+	// approvalHook, when set, gates Manual install plan approval on a caller-supplied check
+	// (e.g. a manual confirmation prompt) instead of approving as soon as the plan appears
+	approvalHook func(installPlan *unstructured.Unstructured) bool
+}
+
+// SetApprovalHook installs a hook that must return true before a Manual-approval install plan is
+// approved; WaitForCondition re-evaluates it every time the install plan changes. Useful for
+// tests and for gating approval on an operator signoff outside this tool.
+func (o *Operator) SetApprovalHook(hook func(installPlan *unstructured.Unstructured) bool) {
+	o.approvalHook = hook
+}
+
+// LastHopTiming returns the HopTiming recorded by the most recent UpgradeOperator call, letting
+// callers that hold an OperatorInterface type-assert to report.TimingReporter
+func (o *Operator) LastHopTiming() report.HopTiming {
+	return o.lastTiming
+}
+
+// LastResolvedVersion returns the CSV name and channel resolved by the most recent
+// UpgradeOperator call, letting callers that hold an OperatorInterface type-assert to
+// report.VersionResolver
+func (o *Operator) LastResolvedVersion() (csv, channel string) {
+	return o.lastCSV, o.lastChannel
 }
 
 const (
@@ -67,6 +127,23 @@ var (
 		Version:  "v1alpha1",
 		Resource: "clusterserviceversions",
 	}
+
+	operatorGroupGVR = schema.GroupVersionResource{
+		Group:    "operators.coreos.com",
+		Version:  "v1",
+		Resource: "operatorgroups",
+	}
+
+	deploymentGVR = schema.GroupVersionResource{
+		Group:    "apps",
+		Version:  "v1",
+		Resource: "deployments",
+	}
+
+	podGVR = schema.GroupVersionResource{
+		Version:  "v1",
+		Resource: "pods",
+	}
 )
 
 // NewOperator creates a new Operator instance
@@ -81,13 +158,40 @@ func NewOperator(config *rest.Config, options config.OperatorConfig) (*Operator,
 		artifact = fmt.Sprintf("%s-%s", options.ArtifactPrefix, options.Name)
 	}
 
+	operatorGroupMode := options.OperatorGroupMode
+	if operatorGroupMode == "" {
+		operatorGroupMode = "OwnNamespace"
+	}
+
+	healthTimeout := options.HealthTimeout
+	if healthTimeout == 0 {
+		healthTimeout = options.Timeout
+	}
+
+	approval := options.Approval
+	if approval == "" {
+		approval = "Manual"
+	}
+
+	resolutionGracePeriod := options.ResolutionGracePeriod
+	if resolutionGracePeriod == 0 {
+		resolutionGracePeriod = 30 * time.Second
+	}
+
 	return &Operator{
-		client:    client,
-		namespace: options.Namespace,
-		name:      options.Name,
-		artifact:  artifact,
-		timeout:   options.Timeout,
-		interval:  options.Interval,
+		client:                client,
+		namespace:             options.Namespace,
+		name:                  options.Name,
+		artifact:              artifact,
+		operatorGroupMode:     operatorGroupMode,
+		watcher:               watch.NewWatcher(client, options.Interval),
+		timeout:               options.Timeout,
+		interval:              options.Interval,
+		healthTimeout:         healthTimeout,
+		approval:              approval,
+		resolutionGracePeriod: resolutionGracePeriod,
+		runID:                 options.RunID,
+		configPath:            options.ConfigPath,
 	}, nil
 }
 
@@ -95,6 +199,13 @@ func (o *Operator) GetResource(ctx context.Context, name, namespace string, gvr
 	return o.client.Resource(gvr).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
 }
 
+// UseNamespace switches the operator to operate against namespace for subsequent calls, so each
+// hop of an UpgradePath.EphemeralNamespace path can run in its own generated namespace
+func (o *Operator) UseNamespace(ctx context.Context, namespace string) error {
+	o.namespace = namespace
+	return nil
+}
+
 func (o *Operator) UpgradeOperator(ctx context.Context, version config.Version) error {
 	// Install artifact version
 	av, err := o.InstallArtifactVersion(ctx, version.BundleVersion)
@@ -108,8 +219,18 @@ func (o *Operator) UpgradeOperator(ctx context.Context, version config.Version)
 	if channel == "" {
 		channel = "stable" // default fallback
 	}
-	if err := o.InstallSubscription(ctx, csv, channel); err != nil {
-		return fmt.Errorf("failed to install subscription: %v", err)
+	approval := version.Approval
+	if approval == "" {
+		approval = o.approval
+	}
+	o.lastCSV = csv
+	o.lastChannel = channel
+	if err := o.InstallSubscription(ctx, csv, channel, approval, version.AllowedCSVVersions, version.FallbackCSV, version.FallbackChannel, version.Name); err != nil {
+		return fmt.Errorf("failed to install subscription: %w", err)
+	}
+
+	if err := o.VerifyOperatorHealth(ctx, csv); err != nil {
+		return fmt.Errorf("operator is not healthy after upgrade: %v", err)
 	}
 
 	return nil
@@ -0,0 +1,112 @@
+package operatorhub
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"golang.org/x/mod/semver"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"knative.dev/pkg/logging"
+
+	"github.com/AlaudaDevops/upgrade-test/pkg/config"
+	"github.com/AlaudaDevops/upgrade-test/pkg/report"
+)
+
+// Preflight evaluates next.Preconditions against the Subscription and CSV currently installed in
+// o.namespace, in the style of the Cluster Version Operator's upgradeable preconditions. prev is
+// the version this hop is upgrading from, currently unused beyond being part of the interface
+// contract for callers that want it logged alongside the report.
+func (o *Operator) Preflight(ctx context.Context, prev, next config.Version) (report.PreflightReport, error) {
+	log := logging.FromContext(ctx)
+	rep := report.PreflightReport{Version: next.Name}
+
+	pre := next.Preconditions
+	if pre.MinPreviousVersion == "" && len(pre.AllowedChannelsFrom) == 0 && len(pre.RequiredCSVAnnotations) == 0 && pre.SkipIfCSVPresent == "" {
+		rep.Satisfied = true
+		rep.Reason = "no preconditions configured"
+		return rep, nil
+	}
+
+	sub, err := o.client.Resource(subscriptionGVR).Namespace(o.namespace).Get(ctx, o.name, metav1.GetOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return rep, fmt.Errorf("failed to get subscription %s to evaluate preconditions: %v", o.name, err)
+	}
+
+	var installedCSV, channel string
+	if sub != nil {
+		installedCSV, _, _ = unstructured.NestedString(sub.Object, "status", "installedCSV")
+		channel, _, _ = unstructured.NestedString(sub.Object, "spec", "channel")
+	}
+
+	if pre.SkipIfCSVPresent != "" && installedCSV == pre.SkipIfCSVPresent {
+		rep.Skipped = true
+		rep.Reason = fmt.Sprintf("csv %s is already installed", installedCSV)
+		log.Infow("preflight satisfied already", "version", next.Name, "reason", rep.Reason)
+		return rep, nil
+	}
+
+	var csvVersion string
+	var annotations map[string]string
+	if installedCSV != "" {
+		csv, err := o.client.Resource(csvGVR).Namespace(o.namespace).Get(ctx, installedCSV, metav1.GetOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			return rep, fmt.Errorf("failed to get csv %s to evaluate preconditions: %v", installedCSV, err)
+		}
+		if csv != nil {
+			csvVersion, _, _ = unstructured.NestedString(csv.Object, "spec", "version")
+			annotations = csv.GetAnnotations()
+		}
+	}
+
+	if pre.MinPreviousVersion != "" {
+		if csvVersion == "" {
+			rep.Reason = fmt.Sprintf("no csv installed, but minPreviousVersion %q is required", pre.MinPreviousVersion)
+			log.Infow("preflight not satisfied", "version", next.Name, "reason", rep.Reason)
+			return rep, nil
+		}
+		if semver.Compare(normalizeSemver(csvVersion), normalizeSemver(pre.MinPreviousVersion)) < 0 {
+			rep.Reason = fmt.Sprintf("installed csv version %s is older than minPreviousVersion %s", csvVersion, pre.MinPreviousVersion)
+			log.Infow("preflight not satisfied", "version", next.Name, "reason", rep.Reason)
+			return rep, nil
+		}
+	}
+
+	if len(pre.AllowedChannelsFrom) > 0 && !contains(pre.AllowedChannelsFrom, channel) {
+		rep.Reason = fmt.Sprintf("current subscription channel %q is not one of %v", channel, pre.AllowedChannelsFrom)
+		log.Infow("preflight not satisfied", "version", next.Name, "reason", rep.Reason)
+		return rep, nil
+	}
+
+	for _, key := range pre.RequiredCSVAnnotations {
+		if _, ok := annotations[key]; !ok {
+			rep.Reason = fmt.Sprintf("installed csv %s is missing required annotation %q", installedCSV, key)
+			log.Infow("preflight not satisfied", "version", next.Name, "reason", rep.Reason)
+			return rep, nil
+		}
+	}
+
+	rep.Satisfied = true
+	rep.Reason = "all preconditions satisfied"
+	log.Infow("preflight satisfied", "version", next.Name, "installedCSV", installedCSV, "channel", channel)
+	return rep, nil
+}
+
+// normalizeSemver prefixes v with "v" if missing, the form golang.org/x/mod/semver requires
+func normalizeSemver(v string) string {
+	if !strings.HasPrefix(v, "v") {
+		return "v" + v
+	}
+	return v
+}
+
+func contains(values []string, target string) bool {
+	for _, v := range values {
+		if v == target {
+			return true
+		}
+	}
+	return false
+}
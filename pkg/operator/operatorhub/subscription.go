@@ -3,9 +3,14 @@ package operatorhub
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/oliveagle/jsonpath"
+	"golang.org/x/mod/semver"
+
+	operatorerrors "github.com/AlaudaDevops/upgrade-test/pkg/operator/errors"
+	"github.com/AlaudaDevops/upgrade-test/pkg/report"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -15,11 +20,14 @@ import (
 	"knative.dev/pkg/logging"
 )
 
-func (o *Operator) InstallSubscription(ctx context.Context, csv string, channel string) error {
+func (o *Operator) InstallSubscription(ctx context.Context, csv string, channel string, approval string, allowedCSVVersions []string, fallbackCSV string, fallbackChannel string, versionName string) error {
 	if csv == "" {
 		return fmt.Errorf("csv is empty")
 	}
 
+	installStart := time.Now()
+	o.lastTiming = report.HopTiming{}
+
 	log := logging.FromContext(ctx)
 	log.Infow("installing subscription", "csv", csv, "namespace", o.namespace)
 	// Delete the subscription and csv if they exist
@@ -31,40 +39,205 @@ func (o *Operator) InstallSubscription(ctx context.Context, csv string, channel
 		return fmt.Errorf("failed to delete old csv: %v", err)
 	}
 
-	log.Infow("creating subscription", "name", o.name, "namespace", o.namespace, "csv", csv, "channel", channel)
-	_, err := o.createSubscription(ctx, o.name, o.namespace, csv, channel)
+	log.Infow("ensuring operator group", "namespace", o.namespace, "mode", o.operatorGroupMode)
+	if err := o.EnsureOperatorGroup(ctx, o.operatorGroupMode, o.namespace); err != nil {
+		return fmt.Errorf("failed to ensure operator group: %v", err)
+	}
+
+	log.Infow("creating subscription", "name", o.name, "namespace", o.namespace, "csv", csv, "channel", channel, "approval", approval)
+	_, err := o.createSubscription(ctx, o.name, o.namespace, csv, channel, approval, versionName)
 	if err != nil {
 		return fmt.Errorf("failed to create subscription: %v", err)
 	}
 
 	log.Infow("waiting for install plan", "name", o.name, "namespace", o.namespace)
-	installPlanName, err := o.waitInstallPlan(ctx, o.name, o.namespace)
+	installPlanName, err := o.waitInstallPlanFor(ctx, o.name, o.namespace, csv, channel, approval, fallbackCSV, fallbackChannel, versionName)
 	if err != nil {
 		return fmt.Errorf("failed to wait for install plan: %v", err)
 	}
+	approvalStart := time.Now()
 
-	log.Infow("approving install plan", "name", o.name, "namespace", o.namespace, "installPlanName", installPlanName)
 	installPlan, err := o.client.Resource(installPlanGVR).Namespace(o.namespace).Get(ctx, installPlanName, metav1.GetOptions{})
 	if err != nil {
 		return fmt.Errorf("failed to get install plan: %v", err)
 	}
+	o.lastTiming.DeprecatedAPIWarnings = deprecatedAPIWarnings(installPlan)
 
-	installPlan.Object["spec"].(map[string]interface{})["approved"] = true
-	_, err = o.client.Resource(installPlanGVR).Namespace(o.namespace).Update(ctx, installPlan, metav1.UpdateOptions{})
-	if err != nil {
-		return fmt.Errorf("failed to update install plan: %v", err)
+	if approval == "Manual" {
+		if err := o.approveInstallPlan(ctx, installPlanName, o.namespace, allowedCSVVersions); err != nil {
+			return fmt.Errorf("failed to approve install plan: %v", err)
+		}
+	} else {
+		log.Infow("install plan approval is Automatic, leaving it to OLM", "installPlanName", installPlanName)
+	}
+	o.lastTiming.ApprovalLatency = time.Since(approvalStart)
+
+	csvReadyStart := time.Now()
+	log.Infow("waiting for install plan to complete", "name", o.name, "namespace", o.namespace, "installPlanName", installPlanName)
+	if err := o.waitInstallPlanComplete(ctx, installPlanName, o.namespace); err != nil {
+		return err
 	}
 
 	log.Infow("waiting for csv to be ready", "name", csv, "namespace", o.namespace)
-	err = o.waitCSVReady(ctx, csv, o.namespace)
-	if err != nil {
-		return fmt.Errorf("failed to wait for csv to be ready: %v", err)
+	if err := o.waitCSVsReady(ctx, csv, installPlanName, o.namespace); err != nil {
+		return fmt.Errorf("failed to wait for csv to be ready: %w", err)
 	}
+	o.lastTiming.CSVReadyLatency = time.Since(csvReadyStart)
+	if err := o.waitDeploymentsReady(ctx, csv, o.namespace); err != nil {
+		return fmt.Errorf("operator deployments not ready after upgrade: %v", err)
+	}
+	o.lastTiming.InstallDuration = time.Since(installStart)
 
 	log.Infow("subscription installed successfully", "name", o.name, "namespace", o.namespace)
 	return nil
 }
 
+// approveInstallPlan patches installPlanName to spec.approved=true, first waiting for
+// o.approvalHook to return true for it if one is set. When allowedCSVVersions is non-empty,
+// every CSV listed in the plan's spec.clusterServiceVersionNames must have a spec.version
+// matching the policy (see csvVersionsAllowed) or the plan is left un-approved and a
+// operatorerrors.ErrInstallPlanVersionNotAllowed is returned, listing the offending CSVs.
+func (o *Operator) approveInstallPlan(ctx context.Context, installPlanName, namespace string, allowedCSVVersions []string) error {
+	log := logging.FromContext(ctx)
+
+	if o.approvalHook != nil {
+		log.Infow("waiting for approval hook", "installPlanName", installPlanName)
+		waitCtx, cancel := context.WithTimeout(ctx, o.timeout)
+		defer cancel()
+		if err := o.watcher.WaitForCondition(waitCtx, installPlanGVR, namespace, installPlanName, func(installPlan *unstructured.Unstructured) (bool, error) {
+			if installPlan == nil {
+				return false, nil
+			}
+			return o.approvalHook(installPlan), nil
+		}); err != nil {
+			return fmt.Errorf("approval hook did not approve install plan %s: %v", installPlanName, err)
+		}
+	}
+
+	installPlan, err := o.client.Resource(installPlanGVR).Namespace(namespace).Get(ctx, installPlanName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get install plan: %v", err)
+	}
+
+	if failure := installPlanFailure(installPlan); failure != nil {
+		log.Errorw("install plan failed terminally, not approving", "installPlanName", installPlanName, "reason", failure.Reason)
+		return failure
+	}
+
+	if len(allowedCSVVersions) > 0 {
+		violation, err := o.checkCSVVersionsAllowed(ctx, installPlan, namespace, allowedCSVVersions)
+		if err != nil {
+			return fmt.Errorf("failed to check csv versions for install plan %s: %v", installPlanName, err)
+		}
+		if violation != nil {
+			log.Errorw("install plan contains out-of-range csv versions, not approving",
+				"installPlanName", installPlanName, "allowed", allowedCSVVersions, "related", violation.Related)
+			return violation
+		}
+	}
+
+	log.Infow("approving install plan", "installPlanName", installPlanName, "namespace", namespace)
+	installPlan.Object["spec"].(map[string]interface{})["approved"] = true
+	labels := installPlan.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[managedLabelKey] = managedLabelValue
+	installPlan.SetLabels(labels)
+	_, err = o.client.Resource(installPlanGVR).Namespace(namespace).Update(ctx, installPlan, metav1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to update install plan: %v", err)
+	}
+	return nil
+}
+
+// checkCSVVersionsAllowed reads installPlan's spec.clusterServiceVersionNames, looks up each
+// CSV's spec.version, and returns an ErrInstallPlanVersionNotAllowed listing every CSV that
+// doesn't match allowedCSVVersions, or nil if they all do
+func (o *Operator) checkCSVVersionsAllowed(ctx context.Context, installPlan *unstructured.Unstructured, namespace string, allowedCSVVersions []string) (*operatorerrors.ErrInstallPlanVersionNotAllowed, error) {
+	names, _, _ := unstructured.NestedStringSlice(installPlan.Object, "spec", "clusterServiceVersionNames")
+
+	var offending []operatorerrors.CSVVersion
+	for _, name := range names {
+		csv, err := o.client.Resource(csvGVR).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get csv %s: %v", name, err)
+		}
+
+		version, _, _ := unstructured.NestedString(csv.Object, "spec", "version")
+		if !csvVersionAllowed(version, allowedCSVVersions) {
+			offending = append(offending, operatorerrors.CSVVersion{Name: name, Version: version})
+		}
+	}
+
+	if len(offending) == 0 {
+		return nil, nil
+	}
+
+	return &operatorerrors.ErrInstallPlanVersionNotAllowed{
+		InstallPlanName: installPlan.GetName(),
+		Allowed:         allowedCSVVersions,
+		Related:         offending,
+	}, nil
+}
+
+// csvVersionAllowed reports whether version matches one of allowed, either as an exact semver
+// match or, when an allowed entry omits the patch component (e.g. "1.2"), as a major.minor match
+func csvVersionAllowed(version string, allowed []string) bool {
+	normalized := normalizeSemver(version)
+	for _, a := range allowed {
+		normalizedAllowed := normalizeSemver(a)
+		if normalized == normalizedAllowed {
+			return true
+		}
+		if semver.MajorMinor(normalizedAllowed) == normalizedAllowed && semver.MajorMinor(normalized) == normalizedAllowed {
+			return true
+		}
+	}
+	return false
+}
+
+// deprecatedAPIWarnings scans an InstallPlan's status.plan[] steps for resources using an
+// apiVersion that's been deprecated or removed upstream, in the style of OLM's
+// installplan_warnings_total metric
+func deprecatedAPIWarnings(installPlan *unstructured.Unstructured) []string {
+	deprecatedGroupVersions := map[string]bool{
+		"extensions/v1beta1":                   true,
+		"apps/v1beta1":                         true,
+		"apps/v1beta2":                         true,
+		"policy/v1beta1":                       true,
+		"rbac.authorization.k8s.io/v1beta1":    true,
+		"apiextensions.k8s.io/v1beta1":         true,
+		"admissionregistration.k8s.io/v1beta1": true,
+	}
+
+	steps, _, _ := unstructured.NestedSlice(installPlan.Object, "status", "plan")
+	var warnings []string
+	for _, s := range steps {
+		step, ok := s.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		resource, _, _ := unstructured.NestedMap(step, "resource")
+		group, _, _ := unstructured.NestedString(resource, "group")
+		version, _, _ := unstructured.NestedString(resource, "version")
+		kind, _, _ := unstructured.NestedString(resource, "kind")
+		name, _, _ := unstructured.NestedString(resource, "name")
+
+		groupVersion := version
+		if group != "" {
+			groupVersion = group + "/" + version
+		}
+
+		if deprecatedGroupVersions[groupVersion] {
+			warnings = append(warnings, fmt.Sprintf("%s/%s uses deprecated apiVersion %s", kind, name, groupVersion))
+		}
+	}
+
+	return warnings
+}
+
 func (o *Operator) deleteResource(ctx context.Context, gvr schema.GroupVersionResource, name, namespace string) error {
 	log := logging.FromContext(ctx)
 
@@ -81,21 +254,98 @@ func (o *Operator) deleteResource(ctx context.Context, gvr schema.GroupVersionRe
 	}
 
 	log.Infow("waiting for resource to be deleted", "name", name, "namespace", namespace)
-	err = wait.PollUntilContextTimeout(ctx, o.interval, o.timeout, true, func(ctx context.Context) (done bool, err error) {
-		_, err = rsAbled.Get(ctx, name, metav1.GetOptions{})
+	waitCtx, cancel := context.WithTimeout(ctx, o.timeout)
+	defer cancel()
+	err = o.watcher.WaitForCondition(waitCtx, gvr, namespace, name, func(obj *unstructured.Unstructured) (bool, error) {
+		return obj == nil, nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete resource %s: %v", name, err)
+	}
+	return nil
+}
+
+// EnsureOperatorGroup creates or updates the OperatorGroup that OLM requires before a
+// Subscription in namespace will progress out of Pending. mode is one of AllNamespaces,
+// OwnNamespace or SingleNamespace; targetNamespaces is only honoured for SingleNamespace
+func (o *Operator) EnsureOperatorGroup(ctx context.Context, mode string, targetNamespaces ...string) error {
+	log := logging.FromContext(ctx)
+
+	var spec map[string]interface{}
+	switch mode {
+	case "AllNamespaces":
+		spec = map[string]interface{}{}
+	case "SingleNamespace":
+		namespaces := make([]interface{}, len(targetNamespaces))
+		for i, ns := range targetNamespaces {
+			namespaces[i] = ns
+		}
+		spec = map[string]interface{}{"targetNamespaces": namespaces}
+	default:
+		mode = "OwnNamespace"
+		spec = map[string]interface{}{"targetNamespaces": []interface{}{o.namespace}}
+	}
+
+	name := o.name + "-og"
+	existing, err := o.client.Resource(operatorGroupGVR).Namespace(o.namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to get operator group: %v", err)
+		}
+
+		og := &unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"apiVersion": "operators.coreos.com/v1",
+				"kind":       "OperatorGroup",
+				"metadata": map[string]interface{}{
+					"name":      name,
+					"namespace": o.namespace,
+				},
+				"spec": spec,
+			},
+		}
+
+		log.Infow("creating operator group", "name", name, "namespace", o.namespace, "mode", mode)
+		_, err = o.client.Resource(operatorGroupGVR).Namespace(o.namespace).Create(ctx, og, metav1.CreateOptions{})
+		if err != nil && !errors.IsAlreadyExists(err) {
+			return fmt.Errorf("failed to create operator group: %v", err)
+		}
+		return nil
+	}
+
+	existing.Object["spec"] = spec
+	log.Infow("updating operator group", "name", name, "namespace", o.namespace, "mode", mode)
+	if _, err := o.client.Resource(operatorGroupGVR).Namespace(o.namespace).Update(ctx, existing, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to update operator group: %v", err)
+	}
+	return nil
+}
+
+// TeardownNamespace deletes namespace and waits for it to be gone, used to tear down the
+// per-hop ephemeral namespaces created for UpgradePath.EphemeralNamespace paths
+func (o *Operator) TeardownNamespace(ctx context.Context, namespace string) error {
+	log := logging.FromContext(ctx)
+	log.Infow("tearing down ephemeral namespace", "namespace", namespace)
+
+	nsClient := o.client.Resource(namespaceGVR)
+	if err := nsClient.Delete(ctx, namespace, metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete namespace %s: %v", namespace, err)
+	}
+
+	err := wait.PollUntilContextTimeout(ctx, o.interval, o.timeout, true, func(ctx context.Context) (done bool, err error) {
+		_, err = nsClient.Get(ctx, namespace, metav1.GetOptions{})
 		if errors.IsNotFound(err) {
-			log.Infow("resource not found, deleting resource", "name", name, "namespace", namespace)
 			return true, nil
 		}
 		return false, err
 	})
 	if err != nil {
-		return fmt.Errorf("failed to delete resource %s: %v", name, err)
+		return fmt.Errorf("failed to delete namespace %s: %v", namespace, err)
 	}
 	return nil
 }
 
-func (o *Operator) createSubscription(ctx context.Context, name, namespace, csv string, channel string) (*unstructured.Unstructured, error) {
+func (o *Operator) createSubscription(ctx context.Context, name, namespace, csv string, channel string, approval string, versionName string) (*unstructured.Unstructured, error) {
 	log := logging.FromContext(ctx)
 
 	_, err := o.client.Resource(namespaceGVR).Create(ctx, &unstructured.Unstructured{
@@ -119,12 +369,14 @@ func (o *Operator) createSubscription(ctx context.Context, name, namespace, csv
 				"name":      name,
 				"namespace": namespace,
 				"labels": map[string]interface{}{
-					"catalog": "platform",
+					"catalog":       "platform",
+					managedLabelKey: managedLabelValue,
 				},
+				"annotations": o.managedAnnotations(versionName),
 			},
 			"spec": map[string]interface{}{
 				"channel":             channel,
-				"installPlanApproval": "Manual",
+				"installPlanApproval": approval,
 				"name":                name,
 				"source":              "platform",
 				"sourceNamespace":     systemNamespace,
@@ -157,70 +409,628 @@ func (o *Operator) createSubscription(ctx context.Context, name, namespace, csv
 	return nil, fmt.Errorf("failed to create subscription after 3 attempts: %v", err)
 }
 
-// waitInstallPlan waits for the subscription to have an install plan and returns the install plan name
-func (o *Operator) waitInstallPlan(ctx context.Context, name, namespace string) (string, error) {
-	var installPlanName string
+// waitInstallPlanFor waits for the subscription to reference an install plan for csv. If the
+// subscription instead reports ResolutionFailed/ConstraintsNotSatisfiable (no install plan will
+// ever appear in that state), it gives OLM o.resolutionGracePeriod to re-resolve on its own
+// (e.g. after a catalog refresh), then either recreates the subscription with fallbackCSV/
+// fallbackChannel if one is configured, or returns the unsatisfiable constraint as a structured
+// ErrSubscriptionUnresolvable instead of silently waiting out the full o.timeout.
+func (o *Operator) waitInstallPlanFor(ctx context.Context, name, namespace, csv, channel, approval, fallbackCSV, fallbackChannel, versionName string) (string, error) {
+	log := logging.FromContext(ctx)
 
-	err := wait.PollUntilContextTimeout(ctx, o.interval, o.timeout, true, func(ctx context.Context) (done bool, err error) {
-		obj, err := o.client.Resource(subscriptionGVR).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
-		if err != nil && !errors.IsNotFound(err) {
-			return false, err
+	waitCtx, cancel := context.WithTimeout(ctx, o.timeout)
+	installPlanName, resolutionFailure, err := o.watchInstallPlanOrResolutionFailure(waitCtx, name, namespace, csv)
+	cancel()
+	if resolutionFailure == nil {
+		if err != nil {
+			return "", fmt.Errorf("timeout waiting for subscription %s to have an install plan for csv %s", name, csv)
 		}
+		return installPlanName, nil
+	}
+
+	log.Errorw("subscription resolution failed, waiting for grace period before intervening",
+		"name", name, "reason", resolutionFailure.Reason, "message", resolutionFailure.Message, "gracePeriod", o.resolutionGracePeriod)
+
+	// The ResolutionFailed condition is still on the subscription, so re-watching right away
+	// would return it again at once: wait out the grace period first, then check again
+	select {
+	case <-time.After(o.resolutionGracePeriod):
+	case <-ctx.Done():
+		return "", resolutionFailure
+	}
+
+	recheckCtx, recheckCancel := context.WithTimeout(ctx, o.timeout)
+	installPlanName, resolutionFailure, err = o.watchInstallPlanOrResolutionFailure(recheckCtx, name, namespace, csv)
+	recheckCancel()
+	if resolutionFailure == nil && err == nil {
+		return installPlanName, nil
+	}
+
+	if fallbackCSV == "" {
+		if resolutionFailure != nil {
+			return "", resolutionFailure
+		}
+		return "", fmt.Errorf("timeout waiting for subscription %s to have an install plan for csv %s", name, csv)
+	}
 
+	log.Infow("recreating subscription with fallback csv/channel after resolution grace period",
+		"name", name, "fallbackCSV", fallbackCSV, "fallbackChannel", fallbackChannel)
+	if err := o.deleteResource(ctx, subscriptionGVR, name, namespace); err != nil {
+		return "", fmt.Errorf("failed to delete unresolvable subscription: %v", err)
+	}
+	if _, err := o.createSubscription(ctx, name, namespace, fallbackCSV, fallbackChannel, approval, versionName); err != nil {
+		return "", fmt.Errorf("failed to recreate subscription with fallback csv: %v", err)
+	}
+
+	fallbackWaitCtx, fallbackCancel := context.WithTimeout(ctx, o.timeout)
+	defer fallbackCancel()
+	installPlanName, resolutionFailure, err = o.watchInstallPlanOrResolutionFailure(fallbackWaitCtx, name, namespace, fallbackCSV)
+	if resolutionFailure != nil {
+		return "", resolutionFailure
+	}
+	if err != nil {
+		return "", fmt.Errorf("timeout waiting for subscription %s to have an install plan for fallback csv %s", name, fallbackCSV)
+	}
+	return installPlanName, nil
+}
+
+// watchInstallPlanOrResolutionFailure waits for the subscription either to reference an install
+// plan whose spec.clusterServiceVersionNames contains csv or whatever status.currentCSV has
+// already advanced to (OLM may resolve a chained upgrade, e.g. v0.1.0 -> v0.2.0 -> v0.3.0, in a
+// single plan and move currentCSV past csv before this call ever observes it), or to report a
+// ResolutionFailed/ConstraintsNotSatisfiable condition. Exactly one of the three results is set:
+// an install plan name, a resolutionFailure, or a non-nil err (ctx timeout or other wait error).
+func (o *Operator) watchInstallPlanOrResolutionFailure(ctx context.Context, name, namespace, csv string) (string, *operatorerrors.ErrSubscriptionUnresolvable, error) {
+	var installPlanName string
+	var resolutionFailure *operatorerrors.ErrSubscriptionUnresolvable
+
+	err := o.watcher.WaitForCondition(ctx, subscriptionGVR, namespace, name, func(obj *unstructured.Unstructured) (bool, error) {
 		if obj == nil {
 			return false, nil
 		}
 
+		conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+		for _, c := range conditions {
+			condition, ok := c.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			condType, _, _ := unstructured.NestedString(condition, "type")
+			reason, _, _ := unstructured.NestedString(condition, "reason")
+			if condType == "ResolutionFailed" && reason == "ConstraintsNotSatisfiable" {
+				message, _, _ := unstructured.NestedString(condition, "message")
+				resolutionFailure = &operatorerrors.ErrSubscriptionUnresolvable{SubscriptionName: name, Reason: reason, Message: message}
+				return true, nil
+			}
+		}
+
 		// Use jsonpath to extract status.installplan.name
-		jsonpathQuery := "$.status.installplan.name"
-		result, err := jsonpath.JsonPathLookup(obj.Object, jsonpathQuery)
+		result, err := jsonpath.JsonPathLookup(obj.Object, "$.status.installplan.name")
 		if err != nil {
 			// Install plan name not found yet, continue waiting
 			return false, nil
 		}
 
-		// Convert result to string
-		if installPlanNameStr, ok := result.(string); ok && installPlanNameStr != "" {
-			installPlanName = installPlanNameStr
-			return true, nil
+		installPlanNameStr, ok := result.(string)
+		if !ok || installPlanNameStr == "" {
+			return false, nil
+		}
+
+		installPlan, err := o.client.Resource(installPlanGVR).Namespace(namespace).Get(ctx, installPlanNameStr, metav1.GetOptions{})
+		if err != nil {
+			return false, nil
 		}
 
-		// Install plan name is empty or not a string, continue waiting
+		currentCSV, _, _ := unstructured.NestedString(obj.Object, "status", "currentCSV")
+
+		names, _, _ := unstructured.NestedStringSlice(installPlan.Object, "spec", "clusterServiceVersionNames")
+		for _, n := range names {
+			if n == csv || (currentCSV != "" && n == currentCSV) {
+				installPlanName = installPlanNameStr
+				return true, nil
+			}
+		}
 		return false, nil
 	})
 
+	if resolutionFailure != nil {
+		return "", resolutionFailure, nil
+	}
 	if err != nil {
-		return "", fmt.Errorf("timeout waiting for subscription %s to have install plan", name)
+		return "", nil, err
 	}
-
 	if installPlanName == "" {
-		return "", fmt.Errorf("install plan name not found for subscription %s", name)
+		return "", nil, fmt.Errorf("install plan for csv %s not found for subscription %s", csv, name)
 	}
 
-	return installPlanName, nil
+	return installPlanName, nil, nil
 }
 
-func (o *Operator) waitCSVReady(ctx context.Context, name, namespace string) error {
-	err := wait.PollUntilContextTimeout(ctx, o.interval, o.timeout, true, func(ctx context.Context) (done bool, err error) {
-		csv, err := o.client.Resource(csvGVR).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
-		if err != nil && !errors.IsNotFound(err) {
-			return false, err
+// waitInstallPlanComplete waits for the install plan to reach status.phase Complete, failing
+// fast on a terminal failure condition (e.g. BundleLookupFailed) or phase Failed instead of
+// waiting out the full timeout. It also polls the bundle-unpack job's pods every o.interval so a
+// bad image surfaces as soon as the pod starts backing off, without waiting for OLM to propagate
+// the failure onto the InstallPlan's own conditions.
+func (o *Operator) waitInstallPlanComplete(ctx context.Context, name, namespace string) error {
+	waitCtx, cancel := context.WithTimeout(ctx, o.timeout)
+	defer cancel()
+
+	type outcome struct {
+		failure *operatorerrors.ErrInstallPlanFailed
+		err     error
+	}
+	resultCh := make(chan outcome, 2)
+
+	go func() {
+		err := o.watcher.WaitForCondition(waitCtx, installPlanGVR, namespace, name, func(installPlan *unstructured.Unstructured) (bool, error) {
+			if installPlan == nil {
+				return false, nil
+			}
+
+			if failure := installPlanFailure(installPlan); failure != nil {
+				return false, failure
+			}
+
+			phase, _, _ := unstructured.NestedString(installPlan.Object, "status", "phase")
+			if phase == "Failed" {
+				message, _, _ := unstructured.NestedString(installPlan.Object, "status", "message")
+				return false, &operatorerrors.ErrInstallPlanFailed{InstallPlanName: name, Reason: "Failed", Message: message}
+			}
+
+			return phase == "Complete", nil
+		})
+		resultCh <- outcome{err: err}
+	}()
+
+	go func() {
+		ticker := time.NewTicker(o.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-waitCtx.Done():
+				return
+			case <-ticker.C:
+				installPlan, err := o.client.Resource(installPlanGVR).Namespace(namespace).Get(waitCtx, name, metav1.GetOptions{})
+				if err != nil {
+					continue
+				}
+				failure, err := o.bundleUnpackPodFailure(waitCtx, installPlan, namespace)
+				if err != nil || failure == nil {
+					continue
+				}
+				resultCh <- outcome{failure: failure}
+				return
+			}
+		}
+	}()
+
+	result := <-resultCh
+	cancel()
+
+	if result.failure != nil {
+		return result.failure
+	}
+	if result.err != nil {
+		return fmt.Errorf("failed to wait for install plan %s to complete: %w", name, result.err)
+	}
+	return nil
+}
+
+// csvNoPhaseGracePeriod bounds how long waitCSVSettled tolerates a CSV with no status.phase at
+// all before treating it as fatal; a CSV that can't progress to even Installing usually means
+// it couldn't be unmarshaled or its spec is malformed, and will never recover on its own
+const csvNoPhaseGracePeriod = 30 * time.Second
+
+// waitCSVsReady waits for every CSV listed in installPlanName's spec.clusterServiceVersionNames
+// to settle, so a chained upgrade plan (e.g. OLM resolving v0.1.0 -> v0.2.0 -> v0.3.0 in one
+// plan) doesn't get missed by only watching targetCSV. Earlier CSVs in the chain are expected to
+// pass through Replacing and be torn down as the chain advances; by the time this is called the
+// install plan has already completed, so if one of them is already gone there's nothing left to
+// watch for it. Only targetCSV must actually reach Succeeded.
+func (o *Operator) waitCSVsReady(ctx context.Context, targetCSV, installPlanName, namespace string) error {
+	installPlan, err := o.client.Resource(installPlanGVR).Namespace(namespace).Get(ctx, installPlanName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get install plan %s: %v", installPlanName, err)
+	}
+
+	names, _, _ := unstructured.NestedStringSlice(installPlan.Object, "spec", "clusterServiceVersionNames")
+	if len(names) == 0 {
+		names = []string{targetCSV}
+	}
+
+	for _, name := range names {
+		if name != targetCSV {
+			if _, err := o.client.Resource(csvGVR).Namespace(namespace).Get(ctx, name, metav1.GetOptions{}); err != nil {
+				// already superseded and cleaned up as the chain advanced past it
+				continue
+			}
+		}
+
+		succeeded, err := o.waitCSVSettled(ctx, name, installPlanName, namespace)
+		if err != nil {
+			return err
 		}
+		if name == targetCSV && !succeeded {
+			return fmt.Errorf("csv %s settled without reaching phase Succeeded", name)
+		}
+	}
+
+	return nil
+}
+
+// waitCSVSettled waits for a single CSV to settle, returning true if it reaches phase Succeeded,
+// or false if it instead passes through phase Replacing and is then removed (expected for an
+// earlier CSV being superseded partway through a chained upgrade). It exits early with an
+// ErrInstallPlanFailed if installPlanName reaches a terminal failure condition in the meantime,
+// or an ErrCSVFailed if the csv itself reaches a terminal Failed phase/reason (or never reports
+// a status.phase at all, see csvNoPhaseGracePeriod). Both are terminal per operatorerrors.IsFatal,
+// so a malformed CSV that never reports a phase is classified the same as one that fails outright.
+func (o *Operator) waitCSVSettled(ctx context.Context, name, installPlanName, namespace string) (bool, error) {
+	waitCtx, cancel := context.WithTimeout(ctx, o.timeout)
+	defer cancel()
+
+	type outcome struct {
+		failure    *operatorerrors.ErrInstallPlanFailed
+		csvFailure *operatorerrors.ErrCSVFailed
+		succeeded  bool
+		err        error
+	}
+	resultCh := make(chan outcome, 2)
+
+	go func() {
+		var csvFailure *operatorerrors.ErrCSVFailed
+		var firstSeenNoPhase time.Time
+		var sawReplacing bool
+
+		err := o.watcher.WaitForCondition(waitCtx, csvGVR, namespace, name, func(csv *unstructured.Unstructured) (bool, error) {
+			if csv == nil {
+				// a csv that was seen Replacing and has now disappeared has settled, just not
+				// as the version that ultimately stays installed
+				return sawReplacing, nil
+			}
+
+			phase, found, _ := unstructured.NestedString(csv.Object, "status", "phase")
+			if !found || phase == "" {
+				if firstSeenNoPhase.IsZero() {
+					firstSeenNoPhase = time.Now()
+					return false, nil
+				}
+				if time.Since(firstSeenNoPhase) > csvNoPhaseGracePeriod {
+					csvFailure = &operatorerrors.ErrCSVFailed{
+						CSVName: name,
+						Reason:  "NoStatusPhase",
+						Message: "csv reported no status.phase after the grace period, it may be malformed",
+					}
+					return true, nil
+				}
+				return false, nil
+			}
+			firstSeenNoPhase = time.Time{}
+
+			if phase == "Failed" {
+				reason, _, _ := unstructured.NestedString(csv.Object, "status", "reason")
+				if operatorerrors.IsTerminalCSVReason(reason) {
+					message, _, _ := unstructured.NestedString(csv.Object, "status", "message")
+					csvFailure = &operatorerrors.ErrCSVFailed{CSVName: name, Reason: reason, Message: message}
+					return true, nil
+				}
+				return false, nil
+			}
+
+			if phase == "Replacing" {
+				sawReplacing = true
+				return false, nil
+			}
+
+			return phase == "Succeeded", nil
+		})
+		if csvFailure != nil {
+			resultCh <- outcome{csvFailure: csvFailure}
+			return
+		}
+		resultCh <- outcome{succeeded: err == nil && !sawReplacing, err: err}
+	}()
+
+	go func() {
+		err := o.watcher.WaitForCondition(waitCtx, installPlanGVR, namespace, installPlanName, func(installPlan *unstructured.Unstructured) (bool, error) {
+			return installPlan != nil && installPlanFailure(installPlan) != nil, nil
+		})
+		if err != nil {
+			resultCh <- outcome{err: err}
+			return
+		}
+		installPlan, getErr := o.client.Resource(installPlanGVR).Namespace(namespace).Get(ctx, installPlanName, metav1.GetOptions{})
+		if getErr == nil {
+			resultCh <- outcome{failure: installPlanFailure(installPlan)}
+		}
+	}()
+
+	result := <-resultCh
+	cancel()
+
+	if result.failure != nil {
+		return false, result.failure
+	}
+	if result.csvFailure != nil {
+		return false, result.csvFailure
+	}
+	if result.err != nil {
+		return false, fmt.Errorf("timeout waiting for csv %s to settle, error: %s", name, result.err.Error())
+	}
+
+	return result.succeeded, nil
+}
+
+// waitDeploymentsReady waits for every Deployment listed in the CSV's spec.install.spec.deployments
+// to have enough available replicas at the latest observed generation, per the operator-policy
+// pattern of walking CSV -> Deployment before declaring an upgrade successful. Deployments that
+// don't become ready in time have their failing pods (ImagePullBackOff, CrashLoopBackOff)
+// surfaced in the returned error.
+func (o *Operator) waitDeploymentsReady(ctx context.Context, csvName, namespace string) error {
+	log := logging.FromContext(ctx)
+
+	csv, err := o.client.Resource(csvGVR).Namespace(namespace).Get(ctx, csvName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get csv %s to check deployment readiness: %v", csvName, err)
+	}
+
+	deployments, _, _ := unstructured.NestedSlice(csv.Object, "spec", "install", "spec", "deployments")
+
+	waitCtx, cancel := context.WithTimeout(ctx, o.healthTimeout)
+	defer cancel()
+
+	for _, d := range deployments {
+		deployment, ok := d.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		deploymentName, _, _ := unstructured.NestedString(deployment, "name")
+		if deploymentName == "" {
+			continue
+		}
+
+		log.Infow("waiting for operator deployment to be ready", "deployment", deploymentName, "namespace", namespace)
+		if err := o.waitDeploymentReady(waitCtx, deploymentName, namespace); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
 
-		if csv == nil {
+// waitDeploymentReady waits for name's Deployment to report enough available replicas at the
+// latest observed generation, describing any ImagePullBackOff/CrashLoopBackOff pods it finds if
+// the wait times out
+func (o *Operator) waitDeploymentReady(ctx context.Context, name, namespace string) error {
+	err := o.watcher.WaitForCondition(ctx, deploymentGVR, namespace, name, func(obj *unstructured.Unstructured) (bool, error) {
+		if obj == nil {
 			return false, nil
 		}
 
-		status, _, _ := unstructured.NestedMap(csv.Object, "status")
-		if phase, ok := status["phase"].(string); ok && phase == "Succeeded" {
-			return true, nil
+		desired, _, _ := unstructured.NestedInt64(obj.Object, "spec", "replicas")
+		if desired == 0 {
+			desired = 1
 		}
 
-		return false, nil
+		available, _, _ := unstructured.NestedInt64(obj.Object, "status", "availableReplicas")
+		observedGeneration, _, _ := unstructured.NestedInt64(obj.Object, "status", "observedGeneration")
+
+		return available >= desired && observedGeneration >= obj.GetGeneration(), nil
 	})
+	if err == nil {
+		return nil
+	}
 
+	if failures := o.podFailures(ctx, namespace, name); len(failures) > 0 {
+		return fmt.Errorf("deployment %s is not ready: %s", name, strings.Join(failures, "; "))
+	}
+	return fmt.Errorf("timeout waiting for deployment %s to be ready: %v", name, err)
+}
+
+// podFailures describes any CrashLoopBackOff/ImagePullBackOff containers among the pods whose
+// name is prefixed by deploymentName, a best-effort match since walking ReplicaSet
+// ownerReferences all the way down isn't worth another round-trip here
+func (o *Operator) podFailures(ctx context.Context, namespace, deploymentName string) []string {
+	pods, err := o.client.Resource(podGVR).Namespace(namespace).List(ctx, metav1.ListOptions{})
 	if err != nil {
-		return fmt.Errorf("timeout waiting for csv %s to be ready, error: %s", name, err.Error())
+		return nil
+	}
+
+	var failures []string
+	for _, pod := range pods.Items {
+		if !strings.HasPrefix(pod.GetName(), deploymentName+"-") {
+			continue
+		}
+
+		statuses, _, _ := unstructured.NestedSlice(pod.Object, "status", "containerStatuses")
+		for _, s := range statuses {
+			status, ok := s.(map[string]interface{})
+			if !ok {
+				continue
+			}
+
+			reason, _, _ := unstructured.NestedString(status, "state", "waiting", "reason")
+			if reason != "ImagePullBackOff" && reason != "CrashLoopBackOff" {
+				continue
+			}
+
+			containerName, _, _ := unstructured.NestedString(status, "name")
+			failures = append(failures, fmt.Sprintf("pod %s container %s is %s", pod.GetName(), containerName, reason))
+		}
+	}
+
+	return failures
+}
+
+// installPlanFailure inspects an InstallPlan's status.conditions for a terminal failure, e.g.
+// an Installed=False condition with reason InstallComponentFailed or BundleLookupFailed, then
+// falls back to bundleLookupFailure for a bundle unpack job that has permanently failed
+func installPlanFailure(installPlan *unstructured.Unstructured) *operatorerrors.ErrInstallPlanFailed {
+	conditions, found, _ := unstructured.NestedSlice(installPlan.Object, "status", "conditions")
+	if found {
+		for _, c := range conditions {
+			condition, ok := c.(map[string]interface{})
+			if !ok {
+				continue
+			}
+
+			condType, _, _ := unstructured.NestedString(condition, "type")
+			status, _, _ := unstructured.NestedString(condition, "status")
+			reason, _, _ := unstructured.NestedString(condition, "reason")
+			message, _, _ := unstructured.NestedString(condition, "message")
+
+			if condType == "Installed" && status == "False" && operatorerrors.IsTerminalReason(reason) {
+				return &operatorerrors.ErrInstallPlanFailed{
+					InstallPlanName: installPlan.GetName(),
+					Reason:          reason,
+					Message:         message,
+				}
+			}
+			if condType == "BundleLookupFailed" && status == "True" {
+				return &operatorerrors.ErrInstallPlanFailed{
+					InstallPlanName: installPlan.GetName(),
+					Reason:          "BundleLookupFailed",
+					Message:         message,
+				}
+			}
+		}
+	}
+
+	return bundleLookupFailure(installPlan)
+}
+
+// bundleLookupFailure inspects an InstallPlan's status.bundleLookups[].conditions for a
+// BundleLookupPending=False condition with reason BundleUnpackFailed, OLM's signal that the
+// bundle unpack job has permanently failed (bad image tag, malformed bundle, etc) and will never
+// progress on its own
+func bundleLookupFailure(installPlan *unstructured.Unstructured) *operatorerrors.ErrInstallPlanFailed {
+	lookups, found, _ := unstructured.NestedSlice(installPlan.Object, "status", "bundleLookups")
+	if !found {
+		return nil
+	}
+
+	for _, l := range lookups {
+		lookup, ok := l.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		conditions, _, _ := unstructured.NestedSlice(lookup, "conditions")
+		for _, c := range conditions {
+			condition, ok := c.(map[string]interface{})
+			if !ok {
+				continue
+			}
+
+			condType, _, _ := unstructured.NestedString(condition, "type")
+			status, _, _ := unstructured.NestedString(condition, "status")
+			reason, _, _ := unstructured.NestedString(condition, "reason")
+			message, _, _ := unstructured.NestedString(condition, "message")
+
+			if condType == "BundleLookupPending" && status == "False" && reason == "BundleUnpackFailed" {
+				return &operatorerrors.ErrInstallPlanFailed{
+					InstallPlanName: installPlan.GetName(),
+					Reason:          reason,
+					Message:         message,
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// bundleUnpackPodFailure scans pods owned by the InstallPlan's bundle-unpack jobs (labeled
+// olm.owner with one of spec.clusterServiceVersionNames) for a container stuck in
+// ImagePullBackOff/ErrImagePull, so a bad bundle image surfaces within one polling interval
+// instead of waiting for OLM to propagate the failure onto the InstallPlan's own conditions
+func (o *Operator) bundleUnpackPodFailure(ctx context.Context, installPlan *unstructured.Unstructured, namespace string) (*operatorerrors.ErrInstallPlanFailed, error) {
+	names, _, _ := unstructured.NestedStringSlice(installPlan.Object, "spec", "clusterServiceVersionNames")
+	if len(names) == 0 {
+		return nil, nil
+	}
+	owners := map[string]bool{}
+	for _, n := range names {
+		owners[n] = true
+	}
+
+	pods, err := o.client.Resource(podGVR).Namespace(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %v", err)
+	}
+
+	for _, pod := range pods.Items {
+		if !owners[pod.GetLabels()["olm.owner"]] {
+			continue
+		}
+
+		statuses, _, _ := unstructured.NestedSlice(pod.Object, "status", "containerStatuses")
+		for _, s := range statuses {
+			containerStatus, ok := s.(map[string]interface{})
+			if !ok {
+				continue
+			}
+
+			reason, _, _ := unstructured.NestedString(containerStatus, "state", "waiting", "reason")
+			if reason != "ImagePullBackOff" && reason != "ErrImagePull" {
+				continue
+			}
+
+			message, _, _ := unstructured.NestedString(containerStatus, "state", "waiting", "message")
+			return &operatorerrors.ErrInstallPlanFailed{
+				InstallPlanName: installPlan.GetName(),
+				Reason:          reason,
+				Message:         fmt.Sprintf("pod %s: %s", pod.GetName(), message),
+			}, nil
+		}
+	}
+
+	return nil, nil
+}
+
+const (
+	// managedLabelKey/managedLabelValue mark every Subscription/InstallPlan this Operator
+	// creates or approves, so they can be found with `kubectl ... -l` and removed in bulk by
+	// CleanupManagedResources, independent of any one hop's own namespace or CSV name
+	managedLabelKey   = "upgrade-test.alaudadevops.io/managed"
+	managedLabelValue = "true"
+
+	runIDAnnotationKey      = "upgrade-test.alaudadevops.io/run-id"
+	versionAnnotationKey    = "upgrade-test.alaudadevops.io/version"
+	configPathAnnotationKey = "upgrade-test.alaudadevops.io/config-path"
+)
+
+// managedAnnotations builds the traceability annotations stamped on every Subscription this
+// Operator creates: the test run ID and source config path it was constructed with, plus the
+// target version name for this particular hop
+func (o *Operator) managedAnnotations(versionName string) map[string]interface{} {
+	return map[string]interface{}{
+		runIDAnnotationKey:      o.runID,
+		versionAnnotationKey:    versionName,
+		configPathAnnotationKey: o.configPath,
+	}
+}
+
+// CleanupManagedResources deletes every Subscription and InstallPlan in o.namespace carrying the
+// upgrade-test.alaudadevops.io/managed label, i.e. everything this Operator has created or
+// approved, letting a caller garbage collect after a test run without guessing at resource names
+func (o *Operator) CleanupManagedResources(ctx context.Context) error {
+	log := logging.FromContext(ctx)
+	listOptions := metav1.ListOptions{LabelSelector: managedLabelKey + "=" + managedLabelValue}
+
+	for _, gvr := range []schema.GroupVersionResource{subscriptionGVR, installPlanGVR} {
+		list, err := o.client.Resource(gvr).Namespace(o.namespace).List(ctx, listOptions)
+		if err != nil {
+			return fmt.Errorf("failed to list managed %s: %v", gvr.Resource, err)
+		}
+
+		for _, item := range list.Items {
+			log.Infow("deleting managed resource", "resource", gvr.Resource, "name", item.GetName(), "namespace", o.namespace)
+			if err := o.client.Resource(gvr).Namespace(o.namespace).Delete(ctx, item.GetName(), metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+				return fmt.Errorf("failed to delete managed %s %s: %v", gvr.Resource, item.GetName(), err)
+			}
+		}
 	}
 
 	return nil
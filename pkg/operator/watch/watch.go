@@ -0,0 +1,157 @@
+// Package watch provides a shared, informer-backed alternative to polling the API server for a
+// resource to reach some condition. Callers waiting on unrelated resources of the same GVR share
+// a single informer instead of each re-listing/re-getting on their own interval.
+package watch
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+)
+
+// Predicate reports whether obj already satisfies the condition a caller is waiting on. obj is
+// nil when the resource does not (or no longer) exist, letting callers wait on deletion too.
+type Predicate func(obj *unstructured.Unstructured) (bool, error)
+
+// Watcher lazily starts one cluster-wide shared informer per GVR on first use and keeps it
+// running for the lifetime of the Watcher, so repeated waits on the same GVR (e.g. InstallPlans
+// across many hops) reuse one watch connection instead of polling independently
+type Watcher struct {
+	client       dynamic.Interface
+	resyncPeriod time.Duration
+
+	mu        sync.Mutex
+	informers map[schema.GroupVersionResource]cache.SharedIndexInformer
+	stopCh    chan struct{}
+}
+
+// NewWatcher creates a Watcher backed by client. resyncPeriod is the informer's periodic full
+// resync interval, independent of event-driven updates
+func NewWatcher(client dynamic.Interface, resyncPeriod time.Duration) *Watcher {
+	return &Watcher{
+		client:       client,
+		resyncPeriod: resyncPeriod,
+		informers:    map[schema.GroupVersionResource]cache.SharedIndexInformer{},
+		stopCh:       make(chan struct{}),
+	}
+}
+
+// Stop shuts down every informer started by this Watcher. Safe to call once per Watcher.
+func (w *Watcher) Stop() {
+	close(w.stopCh)
+}
+
+// informerFor returns the shared, cluster-wide informer for gvr, starting it and waiting for its
+// initial cache sync on first use
+func (w *Watcher) informerFor(gvr schema.GroupVersionResource) cache.SharedIndexInformer {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if informer, ok := w.informers[gvr]; ok {
+		return informer
+	}
+
+	factory := dynamicinformer.NewDynamicSharedInformerFactory(w.client, w.resyncPeriod)
+	informer := factory.ForResource(gvr).Informer()
+	w.informers[gvr] = informer
+
+	go informer.Run(w.stopCh)
+	cache.WaitForCacheSync(w.stopCh, informer.HasSynced)
+
+	return informer
+}
+
+// WaitForCondition blocks until predicate reports done for the object identified by
+// namespace/name under gvr (namespace must be empty for cluster-scoped resources, since a
+// non-empty namespace is matched against the object's own), ctx is cancelled/times out, or
+// predicate returns an error
+func (w *Watcher) WaitForCondition(ctx context.Context, gvr schema.GroupVersionResource, namespace, name string, predicate Predicate) error {
+	informer := w.informerFor(gvr)
+
+	key := name
+	if namespace != "" {
+		key = namespace + "/" + name
+	}
+
+	// matches reports the unstructured object (nil if obj represents a deletion) for events
+	// that concern the resource we're waiting on; ok is false for events about other objects
+	matches := func(obj interface{}) (u *unstructured.Unstructured, deleted, ok bool) {
+		if tombstone, isTombstone := obj.(cache.DeletedFinalStateUnknown); isTombstone {
+			obj = tombstone.Obj
+			deleted = true
+		}
+		u, isUnstructured := obj.(*unstructured.Unstructured)
+		if !isUnstructured || u.GetName() != name {
+			return nil, false, false
+		}
+		if namespace != "" && u.GetNamespace() != namespace {
+			return nil, false, false
+		}
+		if deleted {
+			return nil, true, true
+		}
+		return u, false, true
+	}
+
+	resultCh := make(chan error, 1)
+	var once sync.Once
+	report := func(err error) {
+		once.Do(func() { resultCh <- err })
+	}
+
+	check := func(obj interface{}) {
+		u, _, ok := matches(obj)
+		if !ok {
+			return
+		}
+		done, err := predicate(u)
+		if err != nil {
+			report(err)
+			return
+		}
+		if done {
+			report(nil)
+		}
+	}
+
+	handle, err := informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    check,
+		UpdateFunc: func(_, newObj interface{}) { check(newObj) },
+		DeleteFunc: check,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to register event handler for %s: %v", gvr.Resource, err)
+	}
+	defer informer.RemoveEventHandler(handle)
+
+	// Fast path: the object may already satisfy predicate in the informer's cache
+	if obj, exists, err := informer.GetStore().GetByKey(key); err == nil {
+		if !exists {
+			if done, err := predicate(nil); err != nil {
+				return err
+			} else if done {
+				return nil
+			}
+		} else if u, _, ok := matches(obj); ok {
+			if done, err := predicate(u); err != nil {
+				return err
+			} else if done {
+				return nil
+			}
+		}
+	}
+
+	select {
+	case err := <-resultCh:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("timed out waiting for condition on %s %s: %v", gvr.Resource, key, ctx.Err())
+	}
+}
@@ -0,0 +1,200 @@
+// Package report records per-hop upgrade-test results and exports them as a Prometheus textfile
+// and a JUnit XML report, so this tool can drop into existing CI dashboards without shell glue.
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// HopTiming captures timing and diagnostic detail about a single upgrade hop, as reported by an
+// operator implementation that supports it (see TimingReporter)
+type HopTiming struct {
+	// InstallDuration is the time spent getting the target version's subscription installed and
+	// its CSV ready
+	InstallDuration time.Duration
+	// ApprovalLatency is the time between the install plan appearing and being approved
+	ApprovalLatency time.Duration
+	// CSVReadyLatency is the time between install plan approval and the CSV reaching Succeeded
+	CSVReadyLatency time.Duration
+	// DeprecatedAPIWarnings lists any deprecated-API usages OLM surfaced on the install plan's
+	// steps, one entry per affected resource
+	DeprecatedAPIWarnings []string
+}
+
+// TimingReporter is implemented by operator.OperatorInterface implementations that can report
+// HopTiming for their most recent UpgradeOperator call. Implementations without per-phase
+// instrumentation (e.g. the local operator) don't implement it; callers should type-assert.
+type TimingReporter interface {
+	LastHopTiming() HopTiming
+}
+
+// VersionResolver is implemented by operator.OperatorInterface implementations that can report
+// the CSV and channel resolved by their most recent UpgradeOperator call, for annotating
+// per-version test reports. Implementations without that notion (e.g. the local and helm
+// operators) don't implement it; callers should type-assert.
+type VersionResolver interface {
+	LastResolvedVersion() (csv, channel string)
+}
+
+// PreflightReport is the outcome of evaluating a hop's config.Preconditions against currently
+// installed cluster state, as reported by an operator implementation that supports it (see
+// operator.PreflightOperator)
+type PreflightReport struct {
+	// Version is the name of the hop's target version being gated
+	Version string
+	// Satisfied is true when every configured precondition passed
+	Satisfied bool
+	// Skipped is true when Preconditions.SkipIfCSVPresent matched the currently installed CSV,
+	// short-circuiting the rest of the checks as already satisfied
+	Skipped bool
+	// Reason explains why Satisfied is false, or why Skipped is true
+	Reason string
+}
+
+// HopResult is the full record of a single (path, version) hop, ready for Prometheus/JUnit export
+type HopResult struct {
+	Path    string
+	Version string
+	Passed  bool
+	// Skipped is true when preflight reported the hop as already satisfied, or when a terminal
+	// failure (per operatorerrors.IsFatal) was absorbed by UpgradePath.FailForward rather than
+	// treated as a hop failure
+	Skipped  bool
+	Err      error
+	Duration time.Duration
+	HopTiming
+}
+
+// Recorder accumulates HopResults across an upgrade-test run for later export
+type Recorder struct {
+	results []HopResult
+}
+
+// NewRecorder creates an empty Recorder
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// Record appends a completed hop's result
+func (r *Recorder) Record(result HopResult) {
+	r.results = append(r.results, result)
+}
+
+// WritePrometheus writes a node-exporter textfile-collector compatible metrics file to path
+func (r *Recorder) WritePrometheus(path string) error {
+	var b strings.Builder
+
+	metric := func(name, help, typ string, value func(HopResult) float64) {
+		fmt.Fprintf(&b, "# HELP %s %s\n", name, help)
+		fmt.Fprintf(&b, "# TYPE %s %s\n", name, typ)
+		for _, res := range r.results {
+			fmt.Fprintf(&b, "%s{path=%q,version=%q} %f\n", name, res.Path, res.Version, value(res))
+		}
+	}
+
+	metric("upgrade_test_hop_success", "Whether the hop's upgrade and test command succeeded (1) or not (0)", "gauge", func(res HopResult) float64 {
+		if res.Passed {
+			return 1
+		}
+		return 0
+	})
+	metric("upgrade_test_hop_duration_seconds", "Total wall time spent on the hop, install plus test command", "gauge", func(res HopResult) float64 {
+		return res.Duration.Seconds()
+	})
+	metric("upgrade_test_hop_install_duration_seconds", "Time spent installing the target operator version", "gauge", func(res HopResult) float64 {
+		return res.InstallDuration.Seconds()
+	})
+	metric("upgrade_test_hop_approval_latency_seconds", "Time between install plan creation and approval", "gauge", func(res HopResult) float64 {
+		return res.ApprovalLatency.Seconds()
+	})
+	metric("upgrade_test_hop_csv_ready_latency_seconds", "Time between install plan approval and CSV ready", "gauge", func(res HopResult) float64 {
+		return res.CSVReadyLatency.Seconds()
+	})
+	metric("installplan_warnings_total", "Deprecated API warnings OLM surfaced on the hop's install plan steps", "gauge", func(res HopResult) float64 {
+		return float64(len(res.DeprecatedAPIWarnings))
+	})
+
+	return os.WriteFile(path, []byte(b.String()), 0644)
+}
+
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Skipped   *struct{}     `xml:"skipped,omitempty"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit writes a JUnit XML report to path, one testsuite per upgrade path and one testcase
+// per hop, so CI dashboards that already parse JUnit can pick this tool's results up directly
+func (r *Recorder) WriteJUnit(path string) error {
+	suites := map[string]*junitTestSuite{}
+	var order []string
+
+	for _, res := range r.results {
+		suite, ok := suites[res.Path]
+		if !ok {
+			suite = &junitTestSuite{Name: res.Path}
+			suites[res.Path] = suite
+			order = append(order, res.Path)
+		}
+
+		tc := junitTestCase{
+			Name:      res.Version,
+			ClassName: res.Path,
+			Time:      fmt.Sprintf("%.3f", res.Duration.Seconds()),
+		}
+		if len(res.DeprecatedAPIWarnings) > 0 {
+			tc.SystemOut = strings.Join(res.DeprecatedAPIWarnings, "\n")
+		}
+
+		suite.Tests++
+		switch {
+		case res.Skipped:
+			tc.Skipped = &struct{}{}
+		case !res.Passed:
+			suite.Failures++
+			message := "hop failed"
+			if res.Err != nil {
+				message = res.Err.Error()
+			}
+			tc.Failure = &junitFailure{Message: message, Text: message}
+		}
+		suite.TestCases = append(suite.TestCases, tc)
+	}
+
+	doc := junitTestSuites{}
+	for _, name := range order {
+		doc.Suites = append(doc.Suites, *suites[name])
+	}
+
+	out, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal junit report: %v", err)
+	}
+
+	content := append([]byte(xml.Header), out...)
+	return os.WriteFile(path, content, 0644)
+}
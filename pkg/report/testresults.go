@@ -0,0 +1,178 @@
+package report
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// TestStatus is the outcome of a single test case
+type TestStatus string
+
+const (
+	TestStatusPassed  TestStatus = "passed"
+	TestStatusFailed  TestStatus = "failed"
+	TestStatusSkipped TestStatus = "skipped"
+)
+
+// TestRunResult is a single test case parsed out of a JUnit XML report or an Allure results
+// directory, normalized to one shape regardless of source
+type TestRunResult struct {
+	Suite          string        `json:"suite"`
+	Case           string        `json:"case"`
+	Status         TestStatus    `json:"status"`
+	Duration       time.Duration `json:"duration"`
+	FailureMessage string        `json:"failureMessage,omitempty"`
+}
+
+// junitReportXML mirrors the subset of JUnit XML this tool reads back, separate from the
+// junitTestSuites family in report.go which is write-only
+type junitReportXML struct {
+	XMLName xml.Name           `xml:"testsuites"`
+	Suites  []junitReportSuite `xml:"testsuite"`
+}
+
+type junitReportSuite struct {
+	Name  string                `xml:"name,attr"`
+	Cases []junitReportTestCase `xml:"testcase"`
+}
+
+type junitReportTestCase struct {
+	Name    string              `xml:"name,attr"`
+	Time    float64             `xml:"time,attr"`
+	Failure *junitReportFailure `xml:"failure"`
+	Skipped *struct{}           `xml:"skipped"`
+}
+
+type junitReportFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// ParseJUnit parses a JUnit XML report at path into a flat list of TestRunResult. It tolerates a
+// lone <testsuite> root as well as a <testsuites> wrapper.
+func ParseJUnit(path string) ([]TestRunResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read junit report %s: %v", path, err)
+	}
+
+	var doc junitReportXML
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		// Fall back to a single <testsuite> root
+		var suite junitReportSuite
+		if err := xml.Unmarshal(data, &suite); err != nil {
+			return nil, fmt.Errorf("failed to parse junit report %s: %v", path, err)
+		}
+		doc.Suites = []junitReportSuite{suite}
+	}
+
+	var results []TestRunResult
+	for _, suite := range doc.Suites {
+		for _, tc := range suite.Cases {
+			result := TestRunResult{
+				Suite:    suite.Name,
+				Case:     tc.Name,
+				Status:   TestStatusPassed,
+				Duration: time.Duration(tc.Time * float64(time.Second)),
+			}
+			switch {
+			case tc.Skipped != nil:
+				result.Status = TestStatusSkipped
+			case tc.Failure != nil:
+				result.Status = TestStatusFailed
+				result.FailureMessage = tc.Failure.Message
+				if result.FailureMessage == "" {
+					result.FailureMessage = tc.Failure.Text
+				}
+			}
+			results = append(results, result)
+		}
+	}
+
+	return results, nil
+}
+
+// allureResult is the subset of an Allure "*-result.json" document this tool reads
+type allureResult struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Start  int64  `json:"start"`
+	Stop   int64  `json:"stop"`
+	Labels []struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	} `json:"labels"`
+	StatusDetails struct {
+		Message string `json:"message"`
+	} `json:"statusDetails"`
+}
+
+// ParseAllure parses every "*-result.json" document in an allure-results directory into a flat
+// list of TestRunResult
+func ParseAllure(dir string) ([]TestRunResult, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*-result.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to glob allure results in %s: %v", dir, err)
+	}
+
+	var results []TestRunResult
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read allure result %s: %v", file, err)
+		}
+
+		var ar allureResult
+		if err := json.Unmarshal(data, &ar); err != nil {
+			return nil, fmt.Errorf("failed to parse allure result %s: %v", file, err)
+		}
+
+		suite := ""
+		for _, label := range ar.Labels {
+			if label.Name == "suite" {
+				suite = label.Value
+				break
+			}
+		}
+
+		result := TestRunResult{
+			Suite:    suite,
+			Case:     ar.Name,
+			Duration: time.Duration(ar.Stop-ar.Start) * time.Millisecond,
+		}
+		switch ar.Status {
+		case "passed":
+			result.Status = TestStatusPassed
+		case "skipped":
+			result.Status = TestStatusSkipped
+		default:
+			result.Status = TestStatusFailed
+			result.FailureMessage = ar.StatusDetails.Message
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// CollectTestResults looks for a junit.xml file and/or an allure-results directory under dir and
+// parses whichever it finds, preferring junit.xml since it's cheaper to parse. Returns an empty,
+// non-nil slice if neither is present.
+func CollectTestResults(dir string) ([]TestRunResult, error) {
+	junitPath := filepath.Join(dir, "junit.xml")
+	if _, err := os.Stat(junitPath); err == nil {
+		return ParseJUnit(junitPath)
+	}
+
+	allureDir := filepath.Join(dir, "allure-results")
+	if info, err := os.Stat(allureDir); err == nil && info.IsDir() {
+		return ParseAllure(allureDir)
+	}
+
+	return []TestRunResult{}, nil
+}
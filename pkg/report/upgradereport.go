@@ -0,0 +1,141 @@
+package report
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"os"
+	"strings"
+)
+
+// VersionTestReport is the aggregated test outcome for a single (path, version) hop, annotated
+// with the bundle version and CSV/channel the operator resolved for it
+type VersionTestReport struct {
+	Path          string          `json:"path"`
+	Version       string          `json:"version"`
+	BundleVersion string          `json:"bundleVersion,omitempty"`
+	CSV           string          `json:"csv,omitempty"`
+	Channel       string          `json:"channel,omitempty"`
+	Results       []TestRunResult `json:"results"`
+}
+
+// Passed, Failed and Skipped count the test cases in Results by status
+func (v VersionTestReport) Passed() int  { return v.countStatus(TestStatusPassed) }
+func (v VersionTestReport) Failed() int  { return v.countStatus(TestStatusFailed) }
+func (v VersionTestReport) Skipped() int { return v.countStatus(TestStatusSkipped) }
+
+func (v VersionTestReport) countStatus(status TestStatus) int {
+	count := 0
+	for _, r := range v.Results {
+		if r.Status == status {
+			count++
+		}
+	}
+	return count
+}
+
+// UpgradeReport aggregates VersionTestReports across every hop of every upgrade path run in this
+// invocation, for export as report.json, report.html and a merged junit-combined.xml
+type UpgradeReport struct {
+	Versions []VersionTestReport `json:"versions"`
+}
+
+// NewUpgradeReport creates an empty UpgradeReport
+func NewUpgradeReport() *UpgradeReport {
+	return &UpgradeReport{}
+}
+
+// Add appends a hop's aggregated test results
+func (r *UpgradeReport) Add(version VersionTestReport) {
+	r.Versions = append(r.Versions, version)
+}
+
+// HasFailures reports whether any recorded test case failed, so callers can fail the run even
+// when the shell command that produced it exited 0
+func (r *UpgradeReport) HasFailures() bool {
+	for _, v := range r.Versions {
+		if v.Failed() > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteJSON writes the full report as indented JSON to path
+func (r *UpgradeReport) WriteJSON(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal upgrade report: %v", err)
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// WriteHTML writes a minimal self-contained HTML summary of the report to path, one table per
+// version, so results can be reviewed without tooling beyond a browser
+func (r *UpgradeReport) WriteHTML(path string) error {
+	var b strings.Builder
+
+	b.WriteString("<!DOCTYPE html>\n<html>\n<head><meta charset=\"utf-8\"><title>Upgrade test report</title></head>\n<body>\n")
+	b.WriteString("<h1>Upgrade test report</h1>\n")
+
+	for _, v := range r.Versions {
+		fmt.Fprintf(&b, "<h2>%s / %s</h2>\n", html.EscapeString(v.Path), html.EscapeString(v.Version))
+		if v.CSV != "" || v.Channel != "" {
+			fmt.Fprintf(&b, "<p>csv: %s, channel: %s</p>\n", html.EscapeString(v.CSV), html.EscapeString(v.Channel))
+		}
+		fmt.Fprintf(&b, "<p>passed: %d, failed: %d, skipped: %d</p>\n", v.Passed(), v.Failed(), v.Skipped())
+
+		b.WriteString("<table border=\"1\" cellpadding=\"4\" cellspacing=\"0\">\n")
+		b.WriteString("<tr><th>suite</th><th>case</th><th>status</th><th>duration</th><th>message</th></tr>\n")
+		for _, result := range v.Results {
+			fmt.Fprintf(&b, "<tr><td>%s</td><td>%s</td><td>%s</td><td>%s</td><td>%s</td></tr>\n",
+				html.EscapeString(result.Suite), html.EscapeString(result.Case), result.Status,
+				result.Duration, html.EscapeString(result.FailureMessage))
+		}
+		b.WriteString("</table>\n")
+	}
+
+	b.WriteString("</body>\n</html>\n")
+
+	return os.WriteFile(path, []byte(b.String()), 0644)
+}
+
+// WriteCombinedJUnit merges every version's Results into a single JUnit XML report at path, one
+// testsuite per (path, version) hop so it drops into the same CI dashboards as Recorder.WriteJUnit
+func (r *UpgradeReport) WriteCombinedJUnit(path string) error {
+	doc := junitTestSuites{}
+
+	for _, v := range r.Versions {
+		suite := junitTestSuite{
+			Name:  fmt.Sprintf("%s/%s", v.Path, v.Version),
+			Tests: len(v.Results),
+		}
+
+		for _, result := range v.Results {
+			tc := junitTestCase{
+				Name:      result.Case,
+				ClassName: result.Suite,
+				Time:      fmt.Sprintf("%.3f", result.Duration.Seconds()),
+			}
+			switch result.Status {
+			case TestStatusSkipped:
+				tc.Skipped = &struct{}{}
+			case TestStatusFailed:
+				suite.Failures++
+				tc.Failure = &junitFailure{Message: result.FailureMessage, Text: result.FailureMessage}
+			}
+			suite.TestCases = append(suite.TestCases, tc)
+		}
+
+		doc.Suites = append(doc.Suites, suite)
+	}
+
+	out, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal combined junit report: %v", err)
+	}
+
+	content := append([]byte(xml.Header), out...)
+	return os.WriteFile(path, content, 0644)
+}